@@ -0,0 +1,398 @@
+package broker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brokerPact = `{
+  "consumer": {"name": "consumera"},
+  "provider": {"name": "testservicea"},
+  "interactions": [],
+  "metadata": {"pactSpecification": {"version": "2.0.0"}}
+}`
+
+// brokerRequest is a request the fake broker received.
+type brokerRequest struct {
+	Method        string
+	Path          string
+	Query         string
+	Authorization string
+	Body          map[string]interface{}
+}
+
+// fakeBroker is an httptest stand-in of the HAL API of a pact broker.
+type fakeBroker struct {
+	server           *httptest.Server
+	publishContracts bool
+	pending          bool
+	matrix           string
+
+	mu       sync.Mutex
+	requests []brokerRequest
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	t.Helper()
+	b := &fakeBroker{publishContracts: true}
+	b.server = httptest.NewServer(http.HandlerFunc(b.serveHTTP))
+	t.Cleanup(b.server.Close)
+	return b
+}
+
+func (b *fakeBroker) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	request := brokerRequest{
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Query:         r.URL.RawQuery,
+		Authorization: r.Header.Get("Authorization"),
+	}
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		_ = json.Unmarshal(data, &request.Body)
+	}
+	b.mu.Lock()
+	b.requests = append(b.requests, request)
+	b.mu.Unlock()
+
+	base := b.server.URL
+	w.Header().Set("Content-Type", "application/hal+json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/":
+		links := map[string]interface{}{
+			"pb:provider-pacts-for-verification": map[string]interface{}{
+				"href":      base + "/pacts/provider/{provider}/for-verification",
+				"templated": true,
+			},
+		}
+		if b.publishContracts {
+			links["pb:publish-contracts"] = map[string]interface{}{"href": base + "/contracts/publish"}
+		}
+		writeJSON(w, map[string]interface{}{"_links": links})
+	case r.Method == http.MethodPost && r.URL.Path == "/pacts/provider/testservicea/for-verification":
+		writeJSON(w, map[string]interface{}{"_embedded": map[string]interface{}{"pacts": []interface{}{
+			map[string]interface{}{
+				"shortDescription": "latest from branch main",
+				"verificationProperties": map[string]interface{}{
+					"pending": b.pending,
+					"notices": []interface{}{map[string]interface{}{"when": "before_verification", "text": "pact is pending"}},
+				},
+				"_links": map[string]interface{}{"self": map[string]interface{}{
+					"href": base + "/pacts/provider/testservicea/consumer/consumera/pact-version/abc",
+				}},
+			},
+		}}})
+	case r.Method == http.MethodGet && r.URL.Path == "/pacts/provider/testservicea/consumer/consumera/pact-version/abc":
+		var pact map[string]interface{}
+		_ = json.Unmarshal([]byte(brokerPact), &pact)
+		pact["_links"] = map[string]interface{}{
+			"pb:consumer-version": map[string]interface{}{
+				"href": base + "/pacticipants/consumera/versions/1.0.0",
+				"name": "1.0.0",
+			},
+			"pb:publish-verification-results": map[string]interface{}{"href": base + "/pacts/abc/verification-results"},
+		}
+		writeJSON(w, pact)
+	case r.Method == http.MethodGet && r.URL.Path == "/matrix" && b.matrix != "":
+		_, _ = io.WriteString(w, b.matrix)
+	case r.Method == http.MethodPost || r.Method == http.MethodPut:
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, map[string]interface{}{})
+	default:
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+type brokerStage struct {
+	t       *testing.T
+	dir     string
+	broker  *fakeBroker
+	client  *Client
+	fetched []FetchedPact
+	pacts   []PublishedPact
+	results []PublishedVerification
+	deploy  *CanIDeployResult
+	err     error
+}
+
+func BrokerTest(t *testing.T) (*brokerStage, *brokerStage, *brokerStage) {
+	t.Helper()
+	s := &brokerStage{t: t, dir: t.TempDir(), broker: newFakeBroker(t)}
+	s.client = &Client{BaseURL: s.broker.server.URL + "/"}
+	return s, s, s
+}
+
+func (s *brokerStage) and() *brokerStage {
+	return s
+}
+
+func (s *brokerStage) a_broker_token(token string) *brokerStage {
+	s.client.Token = token
+	return s
+}
+
+func (s *brokerStage) the_broker_marks_pacts_as_pending() *brokerStage {
+	s.broker.pending = true
+	return s
+}
+
+func (s *brokerStage) a_broker_that_cannot_publish_contracts() *brokerStage {
+	s.broker.publishContracts = false
+	return s
+}
+
+func (s *brokerStage) the_matrix(matrix string) *brokerStage {
+	s.broker.matrix = matrix
+	return s
+}
+
+func (s *brokerStage) a_consumer_pact_in_target() *brokerStage {
+	s.writeFile(filepath.Join("target", "consumera-testservicea.json"), brokerPact)
+	return s
+}
+
+func (s *brokerStage) a_verification_result(name string, success bool) *brokerStage {
+	result, err := json.Marshal(verificationResult{
+		Success:                    success,
+		ProviderApplicationVersion: "2.0.0",
+		ProviderVersionBranch:      "main",
+		BuildURL:                   "https://ci.example.com/builds/1",
+		TestResults: json.RawMessage(`{"consumer": "consumera", "consumerVersion": "1.0.0",
+			"interactions": [{"description": "Request for an organisation", "status": "failed"}]}`),
+	})
+	require.NoError(s.t, err)
+	s.writeFile(filepath.Join("build", "pact-verifications", name), string(result))
+	s.writeFile(filepath.Join("build", "pact-verifications", "output-"+name), `{"examples": []}`)
+	return s
+}
+
+func (s *brokerStage) a_local_pact(name string) *brokerStage {
+	s.writeFile(filepath.Join("build", "incoming-pacts", name), brokerPact)
+	return s
+}
+
+func (s *brokerStage) writeFile(name, content string) {
+	path := filepath.Join(s.dir, name)
+	require.NoError(s.t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	require.NoError(s.t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func (s *brokerStage) pacts_are_fetched_with(selectors ...ConsumerVersionSelector) *brokerStage {
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	s.fetched, s.err = s.client.FetchPacts(context.Background(), FetchOptions{
+		Provider:              "testservicea",
+		Selectors:             selectors,
+		ProviderVersionBranch: "main",
+		IncludePending:        true,
+		IncludeWIPPactsSince:  &since,
+		Dir:                   filepath.Join(s.dir, "build", "incoming-pacts"),
+	})
+	return s
+}
+
+func (s *brokerStage) the_pacts_are_published() *brokerStage {
+	s.pacts, s.err = s.client.PublishPacts(context.Background(), PublishPactsOptions{
+		Dir:             filepath.Join(s.dir, "target"),
+		ConsumerVersion: "1.0.0",
+		Branch:          "main",
+		Tags:            []string{"dev"},
+		BuildURL:        "https://ci.example.com/builds/1",
+	})
+	return s
+}
+
+func (s *brokerStage) the_verification_results_are_published() *brokerStage {
+	s.results, s.err = s.client.PublishVerificationResults(context.Background(), PublishVerificationsOptions{
+		Dir:                 filepath.Join(s.dir, "build", "pact-verifications"),
+		PactsDir:            filepath.Join(s.dir, "build", "incoming-pacts"),
+		ProviderVersionTags: []string{"dev"},
+	})
+	return s
+}
+
+func (s *brokerStage) can_i_deploy_is_asked_for(environment string) *brokerStage {
+	s.deploy, s.err = s.client.CanIDeploy(context.Background(), CanIDeployOptions{
+		Pacticipant: "consumera",
+		Version:     "1.0.0",
+		Environment: environment,
+	})
+	return s
+}
+
+func (s *brokerStage) no_error() *brokerStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *brokerStage) the_broker_received(method, path string) *brokerRequest {
+	s.t.Helper()
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	for i := range s.broker.requests {
+		if s.broker.requests[i].Method == method && s.broker.requests[i].Path == path {
+			return &s.broker.requests[i]
+		}
+	}
+	s.t.Fatalf("broker did not receive %s %s", method, path)
+	return nil
+}
+
+func (s *brokerStage) the_broker_was_asked_for_pacts_with(selector map[string]interface{}) *brokerStage {
+	request := s.the_broker_received(http.MethodPost, "/pacts/provider/testservicea/for-verification")
+	assert.Equal(s.t, []interface{}{selector}, request.Body["consumerVersionSelectors"])
+	assert.Equal(s.t, "main", request.Body["providerVersionBranch"])
+	assert.Equal(s.t, true, request.Body["includePendingStatus"])
+	assert.Equal(s.t, "2024-01-02T00:00:00Z", request.Body["includeWipPactsSince"])
+	return s
+}
+
+func (s *brokerStage) the_pact_was_fetched(pending bool) *brokerStage {
+	require.Len(s.t, s.fetched, 1)
+	pact := s.fetched[0]
+	assert.Equal(s.t, "consumera", pact.Consumer)
+	assert.Equal(s.t, "1.0.0", pact.ConsumerVersion)
+	assert.Equal(s.t, "latest from branch main", pact.Description)
+	assert.Equal(s.t, pending, pact.Pending)
+	assert.Equal(s.t, []string{"pact is pending"}, pact.Notices)
+	assert.Equal(s.t, filepath.Join(s.dir, "build", "incoming-pacts", "consumera-testservicea-1.0.0.json"), pact.File)
+
+	data, err := os.ReadFile(pact.File)
+	require.NoError(s.t, err)
+	assert.Contains(s.t, string(data), "pb:publish-verification-results")
+	var written struct {
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	require.NoError(s.t, json.Unmarshal(data, &written))
+	if pending {
+		assert.Equal(s.t, true, written.Metadata["pending"])
+	} else {
+		assert.NotContains(s.t, written.Metadata, "pending")
+	}
+	return s
+}
+
+func (s *brokerStage) the_contracts_were_published() *brokerStage {
+	require.Len(s.t, s.pacts, 1)
+	assert.Equal(s.t, "testservicea", s.pacts[0].Provider)
+
+	request := s.the_broker_received(http.MethodPost, "/contracts/publish")
+	assert.Equal(s.t, "consumera", request.Body["pacticipantName"])
+	assert.Equal(s.t, "1.0.0", request.Body["pacticipantVersionNumber"])
+	assert.Equal(s.t, "main", request.Body["branch"])
+	assert.Equal(s.t, []interface{}{"dev"}, request.Body["tags"])
+	assert.Equal(s.t, "https://ci.example.com/builds/1", request.Body["buildUrl"])
+
+	contracts, _ := request.Body["contracts"].([]interface{})
+	require.Len(s.t, contracts, 1)
+	contract, _ := contracts[0].(map[string]interface{})
+	assert.Equal(s.t, "testservicea", contract["providerName"])
+	content, err := base64.StdEncoding.DecodeString(contract["content"].(string))
+	require.NoError(s.t, err)
+	assert.JSONEq(s.t, brokerPact, string(content))
+	return s
+}
+
+func (s *brokerStage) the_pact_was_published_separately() *brokerStage {
+	request := s.the_broker_received(http.MethodPut, "/pacts/provider/testservicea/consumer/consumera/version/1.0.0")
+	assert.Equal(s.t, "consumera", request.Body["consumer"].(map[string]interface{})["name"])
+	s.the_broker_received(http.MethodPut, "/pacticipants/consumera/branches/main/versions/1.0.0")
+	s.the_broker_received(http.MethodPut, "/pacticipants/consumera/versions/1.0.0/tags/dev")
+	return s
+}
+
+func (s *brokerStage) the_verification_result_was_published(success bool) *brokerStage {
+	require.Len(s.t, s.results, 1)
+	assert.Equal(s.t, "testservicea", s.results[0].Provider)
+
+	request := s.the_broker_received(http.MethodPost, "/pacts/abc/verification-results")
+	assert.Equal(s.t, success, request.Body["success"])
+	assert.Equal(s.t, "2.0.0", request.Body["providerApplicationVersion"])
+	assert.Equal(s.t, "https://ci.example.com/builds/1", request.Body["buildUrl"])
+	assert.Equal(s.t, map[string]interface{}{
+		"consumer": "consumera", "consumerVersion": "1.0.0",
+		"interactions": []interface{}{
+			map[string]interface{}{"description": "Request for an organisation", "status": "failed"},
+		},
+	}, request.Body["testResults"])
+	assert.Equal(s.t, map[string]interface{}{
+		"consumer": "consumera", "consumerVersion": "1.0.0",
+		"interactions": []interface{}{
+			map[string]interface{}{"description": "Request for an organisation", "status": "failed"},
+		},
+	}, request.Body["testResults"])
+	s.the_broker_received(http.MethodPut, "/pacticipants/testservicea/branches/main/versions/2.0.0")
+	s.the_broker_received(http.MethodPut, "/pacticipants/testservicea/versions/2.0.0/tags/dev")
+	return s
+}
+
+func (s *brokerStage) no_verification_results_were_published() *brokerStage {
+	assert.Empty(s.t, s.results)
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	for _, request := range s.broker.requests {
+		assert.NotEqual(s.t, http.MethodPost, request.Method, "unexpected request to %s", request.Path)
+	}
+	return s
+}
+
+func (s *brokerStage) the_version_is_deployable(deployable bool, reason string) *brokerStage {
+	require.NotNil(s.t, s.deploy)
+	assert.Equal(s.t, deployable, s.deploy.Deployable)
+	assert.Equal(s.t, reason, s.deploy.Reason)
+	return s
+}
+
+func (s *brokerStage) the_matrix_was_queried_with(query string) *brokerStage {
+	request := s.the_broker_received(http.MethodGet, "/matrix")
+	assert.Equal(s.t, query, request.Query)
+	return s
+}
+
+func (s *brokerStage) the_matrix_row_is(success *bool) *brokerStage {
+	require.Len(s.t, s.deploy.Matrix, 1)
+	row := s.deploy.Matrix[0]
+	assert.Equal(s.t, "consumera", row.Consumer)
+	assert.Equal(s.t, "1.0.0", row.ConsumerVersion)
+	assert.Equal(s.t, "testservicea", row.Provider)
+	assert.Equal(s.t, "2.0.0", row.ProviderVersion)
+	assert.Equal(s.t, success, row.Success)
+	return s
+}
+
+func (s *brokerStage) the_broker_responded_with_status(status int) *brokerStage {
+	var responseErr *ResponseError
+	require.True(s.t, errors.As(s.err, &responseErr), "error %v is not a ResponseError", s.err)
+	assert.Equal(s.t, status, responseErr.StatusCode)
+	assert.Contains(s.t, responseErr.Body, "not found")
+	return s
+}
+
+func (s *brokerStage) every_request_was_authorized_with(authorization string) *brokerStage {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	require.NotEmpty(s.t, s.broker.requests)
+	for _, request := range s.broker.requests {
+		assert.Equal(s.t, authorization, request.Authorization, "%s %s", request.Method, request.Path)
+	}
+	return s
+}
+
+func boolPointer(b bool) *bool {
+	return &b
+}
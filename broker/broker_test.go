@@ -0,0 +1,167 @@
+package broker
+
+import (
+	"net/http"
+	"testing"
+)
+
+const deployableMatrix = `{
+  "summary": {"deployable": true, "reason": "All required verification results are published and successful"},
+  "matrix": [{
+    "consumer": {"name": "consumera", "version": {"number": "1.0.0"}},
+    "provider": {"name": "testservicea", "version": {"number": "2.0.0"}},
+    "verificationResult": {"success": true}
+  }]
+}`
+
+const unverifiedMatrix = `{
+  "summary": {"deployable": null, "reason": "There is no verified pact between consumera and testservicea"},
+  "matrix": [{
+    "consumer": {"name": "consumera", "version": {"number": "1.0.0"}},
+    "provider": {"name": "testservicea", "version": {"number": "2.0.0"}},
+    "verificationResult": null
+  }]
+}`
+
+func TestBroker_PactsAreFetchedWithSelectors(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		the_broker_marks_pacts_as_pending()
+
+	when.
+		pacts_are_fetched_with(ConsumerVersionSelector{MainBranch: true})
+
+	then.
+		no_error().and().
+		the_broker_was_asked_for_pacts_with(map[string]interface{}{"mainBranch": true}).and().
+		the_pact_was_fetched(true)
+}
+
+func TestBroker_PactsThatAreNotPendingAreNotMarked(t *testing.T) {
+	_, when, then := BrokerTest(t)
+
+	when.
+		pacts_are_fetched_with(ConsumerVersionSelector{Branch: "feature-x"})
+
+	then.
+		no_error().and().
+		the_broker_was_asked_for_pacts_with(map[string]interface{}{"branch": "feature-x"}).and().
+		the_pact_was_fetched(false)
+}
+
+func TestBroker_PactsArePublishedAsContracts(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		a_consumer_pact_in_target()
+
+	when.
+		the_pacts_are_published()
+
+	then.
+		no_error().and().
+		the_contracts_were_published()
+}
+
+func TestBroker_PactsArePublishedSeparatelyToOlderBrokers(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		a_broker_that_cannot_publish_contracts().and().
+		a_consumer_pact_in_target()
+
+	when.
+		the_pacts_are_published()
+
+	then.
+		no_error().and().
+		the_pact_was_published_separately()
+}
+
+func TestBroker_VerificationResultsArePublishedToFetchedPacts(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		pacts_are_fetched_with(ConsumerVersionSelector{Consumer: "consumera", Latest: true}).and().
+		a_verification_result("consumera-testservicea-1.0.0.json", false)
+
+	when.
+		the_verification_results_are_published()
+
+	then.
+		no_error().and().
+		the_verification_result_was_published(false)
+}
+
+func TestBroker_VerificationResultsOfLocalPactsAreSkipped(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		a_local_pact("testservicea.json").and().
+		a_verification_result("testservicea.json", true)
+
+	when.
+		the_verification_results_are_published()
+
+	then.
+		no_error().and().
+		no_verification_results_were_published()
+}
+
+func TestBroker_CanIDeployToEnvironment(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		the_matrix(deployableMatrix)
+
+	when.
+		can_i_deploy_is_asked_for("production")
+
+	then.
+		no_error().and().
+		the_matrix_was_queried_with("environment=production&latestby=cvp&q%5B%5D%5Bpacticipant%5D=consumera&q%5B%5D%5Bversion%5D=1.0.0").and().
+		the_version_is_deployable(true, "All required verification results are published and successful").and().
+		the_matrix_row_is(boolPointer(true))
+}
+
+func TestBroker_UnverifiedVersionCannotBeDeployed(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		the_matrix(unverifiedMatrix)
+
+	when.
+		can_i_deploy_is_asked_for("")
+
+	then.
+		no_error().and().
+		the_matrix_was_queried_with("latest=true&latestby=cvp&q%5B%5D%5Bpacticipant%5D=consumera&q%5B%5D%5Bversion%5D=1.0.0").and().
+		the_version_is_deployable(false, "There is no verified pact between consumera and testservicea").and().
+		the_matrix_row_is(nil)
+}
+
+func TestBroker_UnsuccessfulResponsesAreResponseErrors(t *testing.T) {
+	_, when, then := BrokerTest(t)
+
+	when.
+		can_i_deploy_is_asked_for("production")
+
+	then.
+		the_broker_responded_with_status(http.StatusNotFound)
+}
+
+func TestBroker_RequestsAreAuthorizedWithToken(t *testing.T) {
+	given, when, then := BrokerTest(t)
+
+	given.
+		a_broker_token("s3cr3t").and().
+		a_consumer_pact_in_target()
+
+	when.
+		the_pacts_are_published()
+
+	then.
+		no_error().and().
+		every_request_was_authorized_with("Bearer s3cr3t")
+}
@@ -0,0 +1,102 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// CanIDeployOptions configures CanIDeploy.
+type CanIDeployOptions struct {
+	Pacticipant string
+	Version     string
+	// Environment is the environment to deploy to. Brokers without environments use To, a tag, instead. With
+	// neither, the version is checked against the latest versions of its integrations.
+	Environment string
+	To          string
+}
+
+// CanIDeployResult is the broker's verdict on deploying a version.
+type CanIDeployResult struct {
+	Deployable bool
+	Reason     string
+	Matrix     []MatrixRow
+}
+
+// MatrixRow is the verification status of one consumer and provider version pair.
+type MatrixRow struct {
+	Consumer        string
+	ConsumerVersion string
+	Provider        string
+	ProviderVersion string
+	// Success is nil when the pact has not been verified.
+	Success *bool
+}
+
+type matrixParticipant struct {
+	Name    string `json:"name"`
+	Version struct {
+		Number string `json:"number"`
+	} `json:"version"`
+}
+
+type matrixResponse struct {
+	Summary struct {
+		// Deployable is null when the outcome is unknown, e.g. because a pact has not been verified yet
+		Deployable *bool  `json:"deployable"`
+		Reason     string `json:"reason"`
+	} `json:"summary"`
+	Matrix []struct {
+		Consumer           matrixParticipant `json:"consumer"`
+		Provider           matrixParticipant `json:"provider"`
+		VerificationResult *struct {
+			Success bool `json:"success"`
+		} `json:"verificationResult"`
+	} `json:"matrix"`
+}
+
+// CanIDeploy asks the broker's matrix whether the pacticipant version is compatible with everything in the target
+// environment. A version whose compatibility is unknown is not deployable.
+func (c *Client) CanIDeploy(ctx context.Context, options CanIDeployOptions) (*CanIDeployResult, error) {
+	if options.Pacticipant == "" || options.Version == "" {
+		return nil, errors.New("can-i-deploy: pacticipant and version are required")
+	}
+	query := url.Values{}
+	query.Add("q[][pacticipant]", options.Pacticipant)
+	query.Add("q[][version]", options.Version)
+	query.Set("latestby", "cvp")
+	if options.Environment != "" {
+		query.Set("environment", options.Environment)
+	} else {
+		query.Set("latest", "true")
+		if options.To != "" {
+			query.Set("tag", options.To)
+		}
+	}
+
+	var response matrixResponse
+	if err := c.getJSON(ctx, c.url("/matrix?"+query.Encode()), &response); err != nil {
+		return nil, fmt.Errorf("can-i-deploy %s %s: %w", options.Pacticipant, options.Version, err)
+	}
+
+	result := &CanIDeployResult{
+		Deployable: response.Summary.Deployable != nil && *response.Summary.Deployable,
+		Reason:     response.Summary.Reason,
+		Matrix:     make([]MatrixRow, 0, len(response.Matrix)),
+	}
+	for _, row := range response.Matrix {
+		matrixRow := MatrixRow{
+			Consumer:        row.Consumer.Name,
+			ConsumerVersion: row.Consumer.Version.Number,
+			Provider:        row.Provider.Name,
+			ProviderVersion: row.Provider.Version.Number,
+		}
+		if row.VerificationResult != nil {
+			success := row.VerificationResult.Success
+			matrixRow.Success = &success
+		}
+		result.Matrix = append(result.Matrix, matrixRow)
+	}
+	return result, nil
+}
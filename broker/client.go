@@ -0,0 +1,183 @@
+// Package broker talks to the HAL API of a pact broker: it fetches the pacts a provider has to verify, publishes
+// consumer pacts and verification results, and asks whether a version can be deployed.
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+//nolint:gochecknoglobals // compiled once
+var unsafeFileNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Client is a client of a pact broker.
+type Client struct {
+	// BaseURL is the URL of the broker, e.g. https://pact-broker.example.com.
+	BaseURL string
+	// Token, if set, authenticates requests with a bearer token.
+	Token string
+	// Username and Password, if set, authenticate requests with basic auth.
+	Username string
+	Password string
+	// HTTPClient sends the requests, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// ResponseError is an unsuccessful response of the broker.
+type ResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s %s: broker responded with status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// halLink is a link of a HAL resource. Templated links have {placeholders} to expand.
+type halLink struct {
+	Href      string `json:"href"`
+	Name      string `json:"name,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+// halResource holds the links of a HAL resource. A relation links to a single resource or to a list of them.
+type halResource struct {
+	Links map[string]json.RawMessage `json:"_links"`
+}
+
+func (r halResource) link(rel string) (halLink, bool) {
+	var link halLink
+	if err := json.Unmarshal(r.Links[rel], &link); err != nil || link.Href == "" {
+		return halLink{}, false
+	}
+	return link, true
+}
+
+// expand fills the placeholders of a templated link with path escaped values.
+func (l halLink) expand(values map[string]string) string {
+	href := l.Href
+	for name, value := range values {
+		href = strings.ReplaceAll(href, "{"+name+"}", url.PathEscape(value))
+	}
+	return href
+}
+
+func (c *Client) url(path string) string {
+	return strings.TrimRight(c.BaseURL, "/") + path
+}
+
+// indexLink looks rel up in the index resource of the broker.
+func (c *Client) indexLink(ctx context.Context, rel string) (halLink, bool, error) {
+	var index halResource
+	if err := c.getJSON(ctx, c.url("/"), &index); err != nil {
+		return halLink{}, false, fmt.Errorf("reading broker index: %w", err)
+	}
+	link, ok := index.link(rel)
+	return link, ok, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, href string, result interface{}) error {
+	data, err := c.send(ctx, http.MethodGet, href, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("decoding response of GET %s: %w", href, err)
+	}
+	return nil
+}
+
+// sendJSON sends body encoded as JSON and decodes the response into result, unless it is nil.
+func (c *Client) sendJSON(ctx context.Context, method, href string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding request body: %w", err)
+	}
+	response, err := c.send(ctx, method, href, data)
+	if err != nil {
+		return err
+	}
+	if result != nil && len(response) > 0 {
+		if err := json.Unmarshal(response, result); err != nil {
+			return fmt.Errorf("decoding response of %s %s: %w", method, href, err)
+		}
+	}
+	return nil
+}
+
+// send makes a request to the broker, returning the response body of a successful response.
+func (c *Client) send(ctx context.Context, method, href string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, href, reader)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/hal+json, application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, href, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response of %s %s: %w", method, href, err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, &ResponseError{Method: method, URL: href, StatusCode: res.StatusCode, Body: string(data)}
+	}
+	return data, nil
+}
+
+// pactParticipants reads the consumer and provider names and the consumer version link of a pact.
+type pactParticipants struct {
+	halResource
+	Consumer struct {
+		Name string `json:"name"`
+	} `json:"consumer"`
+	Provider struct {
+		Name string `json:"name"`
+	} `json:"provider"`
+}
+
+func parsePactParticipants(data []byte) (pactParticipants, error) {
+	var pact pactParticipants
+	if err := json.Unmarshal(data, &pact); err != nil {
+		return pact, fmt.Errorf("parsing pact: %w", err)
+	}
+	if pact.Consumer.Name == "" || pact.Provider.Name == "" {
+		return pact, errors.New("pact has no consumer or provider name")
+	}
+	return pact, nil
+}
+
+func sanitizeFileName(name string) string {
+	return unsafeFileNameCharacters.ReplaceAllString(name, "_")
+}
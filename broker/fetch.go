@@ -0,0 +1,207 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ConsumerVersionSelector picks the consumer versions whose pacts a provider verifies, see
+// https://docs.pact.io/pact_broker/advanced_topics/consumer_version_selectors.
+type ConsumerVersionSelector struct {
+	Consumer           string `json:"consumer,omitempty"`
+	Tag                string `json:"tag,omitempty"`
+	FallbackTag        string `json:"fallbackTag,omitempty"`
+	Latest             bool   `json:"latest,omitempty"`
+	Branch             string `json:"branch,omitempty"`
+	MainBranch         bool   `json:"mainBranch,omitempty"`
+	MatchingBranch     bool   `json:"matchingBranch,omitempty"`
+	DeployedOrReleased bool   `json:"deployedOrReleased,omitempty"`
+	Deployed           bool   `json:"deployed,omitempty"`
+	Released           bool   `json:"released,omitempty"`
+	Environment        string `json:"environment,omitempty"`
+}
+
+// FetchOptions configures FetchPacts.
+type FetchOptions struct {
+	Provider string
+	// Selectors pick the consumer versions to verify. Without selectors the broker returns the latest pact of
+	// every consumer.
+	Selectors []ConsumerVersionSelector
+	// ProviderVersionBranch and ProviderVersionTags describe the provider version about to be verified, which the
+	// broker uses to work out which pacts are pending for it.
+	ProviderVersionBranch string
+	ProviderVersionTags   []string
+	// IncludePending asks the broker to mark the pacts the provider has never successfully verified as pending.
+	IncludePending bool
+	// IncludeWIPPactsSince, if set, adds the work in progress pacts published since then.
+	IncludeWIPPactsSince *time.Time
+	// Dir is the directory the pacts are written to, build/incoming-pacts by default.
+	Dir string
+}
+
+// FetchedPact is a pact FetchPacts wrote to disk.
+type FetchedPact struct {
+	// File is the path the pact was written to.
+	File            string
+	URL             string
+	Consumer        string
+	ConsumerVersion string
+	// Description explains why the broker selected the pact.
+	Description string
+	// Pending pacts have not yet been successfully verified by the provider, so their failures should not fail the
+	// build.
+	Pending bool
+	// WIP marks work in progress pacts, which are also pending.
+	WIP     bool
+	Notices []string
+}
+
+type pactsForVerificationRequest struct {
+	ConsumerVersionSelectors []ConsumerVersionSelector `json:"consumerVersionSelectors"`
+	ProviderVersionBranch    string                    `json:"providerVersionBranch,omitempty"`
+	ProviderVersionTags      []string                  `json:"providerVersionTags,omitempty"`
+	IncludePendingStatus     bool                      `json:"includePendingStatus"`
+	IncludeWIPPactsSince     string                    `json:"includeWipPactsSince,omitempty"`
+}
+
+type pactsForVerificationResponse struct {
+	Embedded struct {
+		Pacts []struct {
+			halResource
+			ShortDescription       string `json:"shortDescription"`
+			VerificationProperties struct {
+				Pending bool `json:"pending"`
+				WIP     bool `json:"wip"`
+				Notices []struct {
+					When string `json:"when"`
+					Text string `json:"text"`
+				} `json:"notices"`
+			} `json:"verificationProperties"`
+		} `json:"pacts"`
+	} `json:"_embedded"`
+}
+
+// FetchPacts asks the broker which pacts the provider has to verify and writes them to Dir, named after their
+// consumer, provider and consumer version. The pacts keep the links the broker adds, which PublishVerificationResults
+// uses to publish their results. Pending and work in progress pacts are marked so in their metadata, so that
+// pacttesting.VerifyProviderPacts reports their failures as warnings.
+func (c *Client) FetchPacts(ctx context.Context, options FetchOptions) ([]FetchedPact, error) {
+	if options.Provider == "" {
+		return nil, errors.New("fetching pacts: provider is required")
+	}
+	link, ok, err := c.indexLink(ctx, "pb:provider-pacts-for-verification")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("fetching pacts: broker does not support fetching pacts for verification")
+	}
+
+	request := pactsForVerificationRequest{
+		ConsumerVersionSelectors: options.Selectors,
+		ProviderVersionBranch:    options.ProviderVersionBranch,
+		ProviderVersionTags:      options.ProviderVersionTags,
+		IncludePendingStatus:     options.IncludePending,
+	}
+	if request.ConsumerVersionSelectors == nil {
+		request.ConsumerVersionSelectors = []ConsumerVersionSelector{}
+	}
+	if options.IncludeWIPPactsSince != nil {
+		request.IncludeWIPPactsSince = options.IncludeWIPPactsSince.UTC().Format(time.RFC3339)
+	}
+	var response pactsForVerificationResponse
+	href := link.expand(map[string]string{"provider": options.Provider})
+	if err := c.sendJSON(ctx, http.MethodPost, href, request, &response); err != nil {
+		return nil, fmt.Errorf("fetching pacts for %s: %w", options.Provider, err)
+	}
+
+	dir := options.Dir
+	if dir == "" {
+		dir = filepath.Join("build", "incoming-pacts")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("creating pact directory: %w", err)
+	}
+
+	fetched := make([]FetchedPact, 0, len(response.Embedded.Pacts))
+	for _, pact := range response.Embedded.Pacts {
+		self, ok := pact.link("self")
+		if !ok {
+			return nil, fmt.Errorf("fetching pacts for %s: pact %q has no self link", options.Provider, pact.ShortDescription)
+		}
+		properties := pact.VerificationProperties
+		fetchedPact := FetchedPact{
+			URL:         self.Href,
+			Description: pact.ShortDescription,
+			Pending:     properties.Pending,
+			WIP:         properties.WIP,
+		}
+		for _, notice := range properties.Notices {
+			fetchedPact.Notices = append(fetchedPact.Notices, notice.Text)
+		}
+		if err := c.writePact(ctx, dir, &fetchedPact); err != nil {
+			return nil, err
+		}
+		fetched = append(fetched, fetchedPact)
+	}
+	return fetched, nil
+}
+
+func (c *Client) writePact(ctx context.Context, dir string, pact *FetchedPact) error {
+	data, err := c.send(ctx, http.MethodGet, pact.URL, nil)
+	if err != nil {
+		return fmt.Errorf("fetching pact: %w", err)
+	}
+	participants, err := parsePactParticipants(data)
+	if err != nil {
+		return fmt.Errorf("fetching pact %s: %w", pact.URL, err)
+	}
+	pact.Consumer = participants.Consumer.Name
+	if version, ok := participants.link("pb:consumer-version"); ok {
+		pact.ConsumerVersion = version.Name
+	}
+
+	name := participants.Consumer.Name + "-" + participants.Provider.Name
+	if pact.ConsumerVersion != "" {
+		name += "-" + pact.ConsumerVersion
+	}
+	if pact.Pending || pact.WIP {
+		if data, err = markPending(data, pact.WIP); err != nil {
+			return fmt.Errorf("fetching pact %s: %w", pact.URL, err)
+		}
+	}
+	pact.File = filepath.Join(dir, sanitizeFileName(name)+".json")
+	if err := os.WriteFile(pact.File, data, 0o600); err != nil {
+		return fmt.Errorf("writing pact '%s': %w", pact.File, err)
+	}
+	return nil
+}
+
+// markPending marks the pact as pending, and as work in progress if wip, in its metadata, where
+// pacttesting.VerifyProviderPacts looks for the markers.
+func markPending(data []byte, wip bool) ([]byte, error) {
+	var pact map[string]interface{}
+	if err := json.Unmarshal(data, &pact); err != nil {
+		return nil, fmt.Errorf("parsing pact: %w", err)
+	}
+	metadata, _ := pact["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+		pact["metadata"] = metadata
+	}
+	metadata["pending"] = true
+	if wip {
+		metadata["wip"] = true
+	}
+	data, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding pact: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,295 @@
+package broker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PublishPactsOptions configures PublishPacts.
+type PublishPactsOptions struct {
+	// Dir holds the pact files to publish, target by default, where the mock service writes them.
+	Dir string
+	// ConsumerVersion is the version of the consumer the pacts were generated by.
+	ConsumerVersion string
+	Branch          string
+	Tags            []string
+	// BuildURL links the published version to the CI build that published it.
+	BuildURL string
+}
+
+// PublishedPact is a pact file PublishPacts published.
+type PublishedPact struct {
+	File     string
+	Consumer string
+	Provider string
+}
+
+type publishContractsRequest struct {
+	PacticipantName          string              `json:"pacticipantName"`
+	PacticipantVersionNumber string              `json:"pacticipantVersionNumber"`
+	Branch                   string              `json:"branch,omitempty"`
+	Tags                     []string            `json:"tags,omitempty"`
+	BuildURL                 string              `json:"buildUrl,omitempty"`
+	Contracts                []publishedContract `json:"contracts"`
+}
+
+type publishedContract struct {
+	ConsumerName  string `json:"consumerName"`
+	ProviderName  string `json:"providerName"`
+	Specification string `json:"specification"`
+	ContentType   string `json:"contentType"`
+	Content       string `json:"content"`
+}
+
+// PublishPacts publishes every pact file in Dir as the given consumer version. Brokers that support it receive all
+// pacts of a consumer in one publication; older brokers get each pact, tag and branch separately.
+func (c *Client) PublishPacts(ctx context.Context, options PublishPactsOptions) ([]PublishedPact, error) {
+	if options.ConsumerVersion == "" {
+		return nil, errors.New("publishing pacts: consumer version is required")
+	}
+	dir := options.Dir
+	if dir == "" {
+		dir = "target"
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("listing pacts in '%s': %w", dir, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("publishing pacts: no pact files in '%s'", dir)
+	}
+
+	byConsumer := make(map[string][]publishedContract)
+	published := make([]PublishedPact, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading pact file '%s': %w", file, err)
+		}
+		participants, err := parsePactParticipants(data)
+		if err != nil {
+			return nil, fmt.Errorf("reading pact file '%s': %w", file, err)
+		}
+		consumer, provider := participants.Consumer.Name, participants.Provider.Name
+		byConsumer[consumer] = append(byConsumer[consumer], publishedContract{
+			ConsumerName:  consumer,
+			ProviderName:  provider,
+			Specification: "pact",
+			ContentType:   "application/json",
+			Content:       base64.StdEncoding.EncodeToString(data),
+		})
+		published = append(published, PublishedPact{File: file, Consumer: consumer, Provider: provider})
+	}
+
+	link, ok, err := c.indexLink(ctx, "pb:publish-contracts")
+	if err != nil {
+		return nil, err
+	}
+	consumers := make([]string, 0, len(byConsumer))
+	for consumer := range byConsumer {
+		consumers = append(consumers, consumer)
+	}
+	sort.Strings(consumers)
+	for _, consumer := range consumers {
+		if ok {
+			err = c.sendJSON(ctx, http.MethodPost, link.Href, publishContractsRequest{
+				PacticipantName:          consumer,
+				PacticipantVersionNumber: options.ConsumerVersion,
+				Branch:                   options.Branch,
+				Tags:                     options.Tags,
+				BuildURL:                 options.BuildURL,
+				Contracts:                byConsumer[consumer],
+			}, nil)
+		} else {
+			err = c.publishPactsSeparately(ctx, consumer, byConsumer[consumer], options)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("publishing pacts of %s: %w", consumer, err)
+		}
+	}
+	return published, nil
+}
+
+// publishPactsSeparately publishes pacts, tags and branch through the endpoints brokers had before contracts could
+// be published at once.
+func (c *Client) publishPactsSeparately(
+	ctx context.Context,
+	consumer string,
+	contracts []publishedContract,
+	options PublishPactsOptions,
+) error {
+	if err := c.tagVersion(ctx, consumer, options.ConsumerVersion, options.Branch, options.Tags); err != nil {
+		return err
+	}
+	for _, contract := range contracts {
+		content, err := base64.StdEncoding.DecodeString(contract.Content)
+		if err != nil {
+			return fmt.Errorf("decoding pact: %w", err)
+		}
+		href := c.url(fmt.Sprintf("/pacts/provider/%s/consumer/%s/version/%s",
+			url.PathEscape(contract.ProviderName), url.PathEscape(consumer), url.PathEscape(options.ConsumerVersion)))
+		if _, err := c.send(ctx, http.MethodPut, href, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagVersion records the branch and tags of a pacticipant version.
+func (c *Client) tagVersion(ctx context.Context, pacticipant, version, branch string, tags []string) error {
+	if branch != "" {
+		href := c.url(fmt.Sprintf("/pacticipants/%s/branches/%s/versions/%s",
+			url.PathEscape(pacticipant), url.PathEscape(branch), url.PathEscape(version)))
+		if err := c.sendJSON(ctx, http.MethodPut, href, struct{}{}, nil); err != nil {
+			return err
+		}
+	}
+	for _, tag := range tags {
+		href := c.url(fmt.Sprintf("/pacticipants/%s/versions/%s/tags/%s",
+			url.PathEscape(pacticipant), url.PathEscape(version), url.PathEscape(tag)))
+		if err := c.sendJSON(ctx, http.MethodPut, href, struct{}{}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishVerificationsOptions configures PublishVerificationResults.
+type PublishVerificationsOptions struct {
+	// Dir holds the verification results written by pacttesting.VerifyProviderPacts, build/pact-verifications by
+	// default.
+	Dir string
+	// PactsDir holds the verified pacts as written by FetchPacts, build/incoming-pacts by default.
+	PactsDir string
+	// ProviderVersionTags are added to the verified provider version.
+	ProviderVersionTags []string
+}
+
+// PublishedVerification is a verification result PublishVerificationResults published.
+type PublishedVerification struct {
+	File            string
+	Provider        string
+	ProviderVersion string
+	Success         bool
+}
+
+// verificationResult is the form of the verification results in build/pact-verifications. TestResults holds the
+// per-interaction results, which are published as they are.
+type verificationResult struct {
+	Success                    bool            `json:"success"`
+	ProviderApplicationVersion string          `json:"providerApplicationVersion"`
+	ProviderVersionBranch      string          `json:"providerVersionBranch,omitempty"`
+	BuildURL                   string          `json:"buildUrl,omitempty"`
+	TestResults                json.RawMessage `json:"testResults,omitempty"`
+}
+
+type publishVerificationRequest struct {
+	Success                    bool            `json:"success"`
+	ProviderApplicationVersion string          `json:"providerApplicationVersion"`
+	BuildURL                   string          `json:"buildUrl,omitempty"`
+	TestResults                json.RawMessage `json:"testResults,omitempty"`
+}
+
+// PublishVerificationResults publishes the verification result of every pact in PactsDir that has one in Dir. The
+// results are published to the link the broker put in the pact when it was fetched; results of pacts that did not
+// come from the broker are skipped. The provider version is given the branch recorded with the result and
+// ProviderVersionTags.
+func (c *Client) PublishVerificationResults(
+	ctx context.Context,
+	options PublishVerificationsOptions,
+) ([]PublishedVerification, error) {
+	dir := options.Dir
+	if dir == "" {
+		dir = filepath.Join("build", "pact-verifications")
+	}
+	pactsDir := options.PactsDir
+	if pactsDir == "" {
+		pactsDir = filepath.Join("build", "incoming-pacts")
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("listing verification results in '%s': %w", dir, err)
+	}
+
+	var published []PublishedVerification
+	tagged := make(map[string]bool)
+	for _, file := range files {
+		name := filepath.Base(file)
+		// output- files hold the raw verifier output next to each result
+		if strings.HasPrefix(name, "output-") {
+			continue
+		}
+		pact, ok, err := readFetchedPact(filepath.Join(pactsDir, name))
+		if err != nil {
+			return nil, err
+		}
+		link, hasLink := pact.link("pb:publish-verification-results")
+		if !ok || !hasLink {
+			log.Infof("skipping verification result %s: no pact with a broker link in '%s'", file, pactsDir)
+			continue
+		}
+
+		var result verificationResult
+		data, err := os.ReadFile(file)
+		if err == nil {
+			err = json.Unmarshal(data, &result)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading verification result '%s': %w", file, err)
+		}
+		if result.ProviderApplicationVersion == "" {
+			return nil, fmt.Errorf("verification result '%s' has no provider version", file)
+		}
+
+		provider, version := pact.Provider.Name, result.ProviderApplicationVersion
+		if key := provider + "\x00" + version; !tagged[key] {
+			if err := c.tagVersion(ctx, provider, version, result.ProviderVersionBranch, options.ProviderVersionTags); err != nil {
+				return nil, fmt.Errorf("tagging %s version %s: %w", provider, version, err)
+			}
+			tagged[key] = true
+		}
+		err = c.sendJSON(ctx, http.MethodPost, link.Href, publishVerificationRequest{
+			Success:                    result.Success,
+			ProviderApplicationVersion: version,
+			BuildURL:                   result.BuildURL,
+			TestResults:                result.TestResults,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("publishing verification result '%s': %w", file, err)
+		}
+		published = append(published, PublishedVerification{
+			File:            file,
+			Provider:        provider,
+			ProviderVersion: version,
+			Success:         result.Success,
+		})
+	}
+	return published, nil
+}
+
+// readFetchedPact reads the pact a verification result belongs to, reporting false if there is none.
+func readFetchedPact(file string) (pactParticipants, bool, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return pactParticipants{}, false, nil
+	}
+	if err != nil {
+		return pactParticipants{}, false, fmt.Errorf("reading pact file '%s': %w", file, err)
+	}
+	pact, err := parsePactParticipants(data)
+	if err != nil {
+		return pactParticipants{}, false, fmt.Errorf("reading pact file '%s': %w", file, err)
+	}
+	return pact, true, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+func runConvert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	output := flags.String("o", "", "file to write the converted pact to (default: stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting convert [-o <file>] <pact file>")
+		fmt.Fprintln(flags.Output(), "converts .yaml/.yml pact fixtures to JSON and .json pacts to YAML")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errFailed
+	}
+
+	input := flags.Arg(0)
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("reading pact file: %w", err)
+	}
+
+	var converted []byte
+	switch strings.ToLower(filepath.Ext(input)) {
+	case ".yaml", ".yml":
+		converted, err = pacttesting.ConvertYAMLToJSON(data)
+	case ".json":
+		converted, err = pacttesting.ConvertJSONToYAML(data)
+	default:
+		return fmt.Errorf("cannot tell the format of '%s': expected a .json, .yaml or .yml file", input)
+	}
+	if err != nil {
+		return fmt.Errorf("converting '%s': %w", input, err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(converted)
+		return err
+	}
+	if err := os.WriteFile(*output, converted, 0o600); err != nil {
+		return fmt.Errorf("writing '%s': %w", *output, err)
+	}
+	return nil
+}
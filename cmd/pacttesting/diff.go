@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting diff [-format text|json] <old pact> <new pact>")
+		fmt.Fprintln(flags.Output(), "exits with status 1 if any change may break the provider")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errFailed
+	}
+
+	diff, err := pacttesting.DiffPactFiles(flags.Arg(0), flags.Arg(1))
+	if err != nil {
+		return fmt.Errorf("comparing pacts: %w", err)
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diff); err != nil {
+			return fmt.Errorf("writing diff: %w", err)
+		}
+	case "text":
+		for _, c := range diff.Changes {
+			fmt.Println(c.String())
+		}
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+
+	if diff.Breaking() {
+		return errFailed
+	}
+	return nil
+}
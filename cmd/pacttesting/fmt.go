@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+func runFmt(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	check := flags.Bool("check", false, "list files that are not in canonical form instead of rewriting them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting fmt [-check] <pact files, directories or globs>")
+		fmt.Fprintln(flags.Output(), "with -check, exits with status 1 if any file is not in canonical form")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+
+	paths, err := expandPactPaths(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	if !*check {
+		changed, err := pacttesting.FormatPactFiles(paths...)
+		for _, path := range changed {
+			fmt.Println(path)
+		}
+		return err
+	}
+
+	unformatted := false
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading pact file: %w", err)
+		}
+		formatted, err := pacttesting.FormatPact(data)
+		if err != nil {
+			return fmt.Errorf("formatting pact file '%s': %w", path, err)
+		}
+		if !bytes.Equal(data, formatted) {
+			fmt.Println(path)
+			unformatted = true
+		}
+	}
+	if unformatted {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	strict := flags.Bool("strict", false, "fail on warnings as well as errors")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting lint [-format text|json] [-strict] <pact files, directories or globs>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+
+	paths, err := expandPactPaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	diagnostics, err := pacttesting.LintPactFiles(paths...)
+	if err != nil {
+		return fmt.Errorf("linting pact files: %w", err)
+	}
+
+	switch *format {
+	case "json":
+		if diagnostics == nil {
+			diagnostics = []pacttesting.Diagnostic{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diagnostics); err != nil {
+			return fmt.Errorf("writing diagnostics: %w", err)
+		}
+	case "text":
+		for _, d := range diagnostics {
+			fmt.Println(d.String())
+		}
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+
+	if pacttesting.HasErrors(diagnostics) || (*strict && len(diagnostics) > 0) {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Command pacttesting provides tooling for maintaining pact files outside of a test run.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// errFailed signals that a command has already reported its failure and only the exit code is left to set.
+var errFailed = errors.New("command failed")
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{name: "convert", summary: "convert pact fixtures between JSON and YAML", run: runConvert},
+		{name: "diff", summary: "show semantic changes between two versions of a pact", run: runDiff},
+		{name: "fmt", summary: "rewrite pact files in canonical form", run: runFmt},
+		{name: "lint", summary: "check pact files for problems", run: runLint},
+		{name: "merge", summary: "merge pact files per provider and consumer", run: runMerge},
+		{name: "openapi-check", summary: "check pact files against the provider's OpenAPI document", run: runOpenAPICheck},
+		{name: "openapi-gen", summary: "generate pact fixtures from the provider's OpenAPI document", run: runOpenAPIGen},
+		{name: "resolve", summary: "print a pact fixture as the loader sends it to the mock service", run: runResolve},
+		{name: "split", summary: "split bulk pact files into smaller ones", run: runSplit},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands() {
+		if c.name != os.Args[1] {
+			continue
+		}
+		if err := c.run(os.Args[2:]); err != nil {
+			if !errors.Is(err, errFailed) {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pacttesting <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", c.name, c.summary)
+	}
+}
+
+// expandPactPaths resolves files, directories and glob patterns given on the command line to pact files.
+// Directories contribute every *.json file they contain.
+func expandPactPaths(args []string) ([]string, error) {
+	return expandPaths(args, "*.json")
+}
+
+// expandFixturePaths is expandPactPaths for fixtures, which may also be written in YAML.
+func expandFixturePaths(args []string) ([]string, error) {
+	return expandPaths(args, "*.json", "*.yaml", "*.yml")
+}
+
+// expandPaths resolves files, directories and glob patterns to files, listing the files of directories that match
+// any of patterns.
+func expandPaths(args []string, patterns ...string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no pact files match '%s'", arg)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("reading '%s': %w", match, err)
+			}
+			if !info.IsDir() {
+				paths = append(paths, match)
+				continue
+			}
+			var files []string
+			for _, pattern := range patterns {
+				found, err := filepath.Glob(filepath.Join(match, pattern))
+				if err != nil {
+					return nil, fmt.Errorf("listing '%s': %w", match, err)
+				}
+				files = append(files, found...)
+			}
+			sort.Strings(files)
+			paths = append(paths, files...)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no pact files found in %s", strings.Join(args, " "))
+	}
+	return paths, nil
+}
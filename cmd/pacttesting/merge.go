@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+func runMerge(args []string) error {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	output := flags.String("o", "", "directory to write merged pacts to (required)")
+	allowConflicts := flags.Bool("allow-conflicts", false, "write merged pacts even if conflicts were found, keeping the first interaction")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting merge -o <dir> [-allow-conflicts] <pact files, directories or globs>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+	if *output == "" {
+		flags.Usage()
+		return errFailed
+	}
+
+	paths, err := expandPactPaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	merged, err := pacttesting.MergePactFiles(paths...)
+	var conflictErr *pacttesting.MergeConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		for _, c := range conflictErr.Conflicts {
+			fmt.Fprintln(os.Stderr, "conflict:", c.String())
+		}
+		if !*allowConflicts {
+			return errFailed
+		}
+	case err != nil:
+		return fmt.Errorf("merging pact files: %w", err)
+	}
+
+	if err := pacttesting.WriteMergedPacts(merged, *output); err != nil {
+		return fmt.Errorf("writing merged pacts: %w", err)
+	}
+	for _, m := range merged {
+		fmt.Printf("%s: %d interactions from %d files (%d duplicates dropped)\n",
+			m.FileName(), m.Interactions(), len(m.Sources), m.Duplicates)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+func runOpenAPICheck(args []string) error {
+	flags := flag.NewFlagSet("openapi-check", flag.ContinueOnError)
+	specPath := flags.String("spec", "", "OpenAPI 3 document of the provider, in JSON or YAML")
+	format := flags.String("format", "text", "output format: text or json")
+	fixtures := flags.Bool("fixtures", false,
+		"check fixtures, converting YAML and resolving fragment references, variables and matcher shorthand")
+	variables := variableFlags{}
+	flags.Var(variables, "var", "set a fixture variable as NAME=value (repeatable), with -fixtures")
+	useEnv := flags.Bool("env", false, "resolve fixture variables that are not set with -var from the environment")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting openapi-check -spec <openapi document> [-fixtures [-var NAME=value]... [-env]] [-format text|json] <pact files, directories or globs>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+	if *specPath == "" {
+		flags.Usage()
+		return errors.New("-spec is required")
+	}
+
+	spec, err := pacttesting.LoadOpenAPISpec(*specPath)
+	if err != nil {
+		return err
+	}
+	expand := expandPactPaths
+	if *fixtures {
+		expand = expandFixturePaths
+	}
+	paths, err := expand(flags.Args())
+	if err != nil {
+		return err
+	}
+	validate := spec.ValidatePactFiles
+	if *fixtures {
+		validate = func(paths ...string) ([]pacttesting.OpenAPIViolation, error) {
+			var violations []pacttesting.OpenAPIViolation
+			for _, path := range paths {
+				loader := &pacttesting.PactLoader{Dir: filepath.Dir(path), Variables: variables, UseEnv: *useEnv}
+				found, err := spec.ValidateFixtures(loader, filepath.Base(path))
+				if err != nil {
+					return nil, err
+				}
+				violations = append(violations, found...)
+			}
+			return violations, nil
+		}
+	}
+	violations, err := validate(paths...)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "json":
+		if violations == nil {
+			violations = []pacttesting.OpenAPIViolation{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(violations); err != nil {
+			return fmt.Errorf("writing violations: %w", err)
+		}
+	case "text":
+		for _, v := range violations {
+			fmt.Println(v.String())
+		}
+	default:
+		return fmt.Errorf("unknown format '%s'", *format)
+	}
+
+	if len(violations) > 0 {
+		return errFailed
+	}
+	return nil
+}
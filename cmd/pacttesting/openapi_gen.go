@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+// listFlags collects repeated string flags.
+type listFlags []string
+
+func (l *listFlags) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlags) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func runOpenAPIGen(args []string) error {
+	flags := flag.NewFlagSet("openapi-gen", flag.ContinueOnError)
+	specPath := flags.String("spec", "", "OpenAPI 3 document of the provider, in JSON or YAML (required)")
+	output := flags.String("o", "", "directory to write the fixtures to (required)")
+	consumer := flags.String("consumer", "", "consumer name of the fixtures (required)")
+	provider := flags.String("provider", "", "provider name of the fixtures, the document title by default")
+	var operations, tags, pathPrefixes listFlags
+	flags.Var(&operations, "operation", "generate the operation with this operationId (repeatable)")
+	flags.Var(&tags, "tag", "generate the operations with this tag (repeatable)")
+	flags.Var(&pathPrefixes, "path-prefix", "generate the operations whose path starts with this prefix (repeatable)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting openapi-gen -spec <openapi document> -o <dir> -consumer <name> [-provider <name>]")
+		fmt.Fprintln(flags.Output(), "       [-operation <id>]... [-tag <tag>]... [-path-prefix <prefix>]...")
+		fmt.Fprintln(flags.Output(), "generates a pact fixture per operation and response; without filters all operations are generated")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+	if *specPath == "" || *output == "" || *consumer == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return errFailed
+	}
+
+	spec, err := pacttesting.LoadOpenAPISpec(*specPath)
+	if err != nil {
+		return err
+	}
+	options := pacttesting.GenerateOptions{Consumer: *consumer, Provider: *provider}
+	if len(operations) > 0 {
+		options.Selectors = append(options.Selectors, pacttesting.WithOperationID(operations...))
+	}
+	for _, tag := range tags {
+		options.Selectors = append(options.Selectors, pacttesting.WithTag(tag))
+	}
+	for _, prefix := range pathPrefixes {
+		options.Selectors = append(options.Selectors, pacttesting.WithPathPrefix(prefix))
+	}
+
+	generated, err := spec.GeneratePacts(options)
+	if err != nil {
+		return err
+	}
+	if len(generated) == 0 {
+		return fmt.Errorf("no operations of '%s' match the filters", *specPath)
+	}
+	if err := pacttesting.WriteGeneratedPacts(generated, *output); err != nil {
+		return fmt.Errorf("writing generated pacts: %w", err)
+	}
+	for _, g := range generated {
+		fmt.Printf("%s: %s %s returns %d\n", g.FileName(), g.Operation.Method, g.Operation.Path, g.Status)
+	}
+	return nil
+}
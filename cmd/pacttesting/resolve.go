@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+// variableFlags collects repeated -var NAME=value flags.
+type variableFlags map[string]string
+
+func (v variableFlags) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v variableFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected NAME=value, got '%s'", value)
+	}
+	v[name] = val
+	return nil
+}
+
+func runResolve(args []string) error {
+	flags := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	variables := variableFlags{}
+	flags.Var(variables, "var", "set a fixture variable as NAME=value (repeatable)")
+	useEnv := flags.Bool("env", false, "resolve variables that are not set with -var from the environment")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting resolve [-var NAME=value]... [-env] <pact fixture>")
+		fmt.Fprintln(flags.Output(), "prints a fixture with $ref fragments, variables and matcher shorthand resolved")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errFailed
+	}
+
+	fixture := flags.Arg(0)
+	loader := &pacttesting.PactLoader{Dir: filepath.Dir(fixture), Variables: variables, UseEnv: *useEnv}
+	resolved, err := loader.ResolvePact(filepath.Base(fixture))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(resolved)
+	return err
+}
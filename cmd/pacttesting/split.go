@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/form3tech-oss/go-pact-testing/v2/pacttesting"
+)
+
+//nolint:gochecknoglobals // lookup table
+var splitGroupings = map[string]pacttesting.SplitGrouping{
+	"interaction": pacttesting.SplitPerInteraction,
+	"state":       pacttesting.SplitPerProviderState,
+	"path":        pacttesting.SplitPerRequestPath,
+	"chunk":       pacttesting.SplitInChunks,
+}
+
+func runSplit(args []string) error {
+	flags := flag.NewFlagSet("split", flag.ContinueOnError)
+	output := flags.String("o", "", "directory to write the split pacts to (required)")
+	groupBy := flags.String("by", "interaction", "grouping: interaction, state, path or chunk")
+	chunkSize := flags.Int("n", 10, "interactions per file when grouping by chunk")
+	index := flags.String("index", "", "file to write the index manifest to, relative to the output directory")
+	format := flags.Bool("fmt", false, "write the split pacts in canonical form")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: pacttesting split -o <dir> [-by interaction|state|path|chunk] [-n size] [-index file] [-fmt] <bulk pact files>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errFailed
+	}
+	grouping, ok := splitGroupings[*groupBy]
+	if *output == "" || !ok {
+		flags.Usage()
+		return errFailed
+	}
+
+	paths, err := expandPactPaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		result, err := pacttesting.SplitPactFile(path, *output, pacttesting.SplitOptions{
+			GroupBy:   grouping,
+			ChunkSize: *chunkSize,
+			IndexPath: *index,
+			Format:    *format,
+		})
+		if err != nil {
+			return fmt.Errorf("splitting pact file: %w", err)
+		}
+		fmt.Printf("%s: split into %d files\n", path, len(result.Files))
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// defaultCoverageReport is the path of the JSON coverage report relative to the working directory.
+const defaultCoverageReport = "target/fixture-coverage.json"
+
+//nolint:gochecknoglobals // coverage is collected across all tests of a package
+var (
+	coverageMu sync.Mutex
+	coverage   *coverageTracker
+)
+
+// coverageTracker records which fixture interactions were loaded, registered with a mock service and matched.
+type coverageTracker struct {
+	// pactFiles maps the absolute path of every loaded pact file to the keys of its interactions.
+	pactFiles map[string][]string
+	// readFiles holds the absolute paths of all fixture files read, including fragments.
+	readFiles map[string]bool
+	// interactions holds the coverage of each interaction by coverageKey.
+	interactions map[string]*InteractionCoverage
+	// pending holds, per mock server, the interactions registered since coverage was last collected from it.
+	pending map[*MockServer][]*InteractionCoverage
+}
+
+// CoverageOptions configures RunWithFixtureCoverage.
+type CoverageOptions struct {
+	// Dir is the fixture directory searched for dead fixture files, <cwd>/pacts by default.
+	Dir string
+	// ReportPath is the file the JSON report is written to, <cwd>/target/fixture-coverage.json by default.
+	ReportPath string
+	// Output receives the console summary, os.Stdout by default.
+	Output io.Writer
+	// FailOnUnused fails an otherwise passing run if there are dead fixture files or interactions that
+	// were never hit.
+	FailOnUnused bool
+}
+
+// CoverageReport lists, for a test run, how the fixtures were used.
+type CoverageReport struct {
+	Files []FileCoverage `json:"files"`
+	// DeadFiles are the fixture files in the fixture directory that were never loaded, not even as a fragment.
+	DeadFiles []string `json:"deadFiles"`
+	// NeverHit are the interactions of loaded pact files that the code under test never called.
+	NeverHit []InteractionCoverage `json:"neverHit"`
+}
+
+// FileCoverage is the coverage of the interactions of a loaded pact file.
+type FileCoverage struct {
+	File         string                `json:"file"`
+	Interactions []InteractionCoverage `json:"interactions"`
+}
+
+// InteractionCoverage is the coverage of a single fixture interaction.
+type InteractionCoverage struct {
+	File           string   `json:"file"`
+	Consumer       string   `json:"consumer"`
+	Provider       string   `json:"provider"`
+	Description    string   `json:"description"`
+	ProviderStates []string `json:"providerStates,omitempty"`
+	Method         string   `json:"method"`
+	Path           string   `json:"path"`
+	// Registered counts how often the interaction was registered with a mock service. Interactions that
+	// selectors always filtered out are never registered.
+	Registered int `json:"registered"`
+	// Matched counts how often the interaction was called while it was registered.
+	Matched int `json:"matched"`
+}
+
+// StartFixtureCoverage starts tracking which fixture interactions are registered with mock services and which
+// of them the code under test calls. Coverage is collected from a mock service before its interactions are
+// reset and before it is stopped; it costs one verification call per test.
+func StartFixtureCoverage() {
+	coverageMu.Lock()
+	defer coverageMu.Unlock()
+	coverage = &coverageTracker{
+		pactFiles:    make(map[string][]string),
+		readFiles:    make(map[string]bool),
+		interactions: make(map[string]*InteractionCoverage),
+		pending:      make(map[*MockServer][]*InteractionCoverage),
+	}
+}
+
+// StopFixtureCoverage collects the coverage of the running mock services, stops tracking and reports the
+// coverage since StartFixtureCoverage. dir is the fixture directory searched for dead fixture files,
+// <cwd>/pacts if empty.
+func StopFixtureCoverage(dir string) (*CoverageReport, error) {
+	for _, server := range pactServers {
+		collectCoverage(server)
+	}
+
+	coverageMu.Lock()
+	tracker := coverage
+	coverage = nil
+	coverageMu.Unlock()
+	if tracker == nil {
+		return nil, errors.New("fixture coverage was not started")
+	}
+	if dir == "" {
+		cwd, _ := os.Getwd()
+		dir = filepath.Join(cwd, "pacts")
+	}
+	return tracker.report(dir)
+}
+
+// RunWithFixtureCoverage runs the tests of a package with fixture coverage, writes the JSON report and prints a
+// summary. It returns the exit code for os.Exit, e.g.
+//
+//	func TestMain(m *testing.M) {
+//		code := pacttesting.RunWithFixtureCoverage(m, pacttesting.CoverageOptions{})
+//		pacttesting.StopMockServers()
+//		os.Exit(code)
+//	}
+func RunWithFixtureCoverage(m *testing.M, options CoverageOptions) int {
+	StartFixtureCoverage()
+	code := m.Run()
+
+	output := options.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	report, err := StopFixtureCoverage(options.Dir)
+	if err == nil {
+		reportPath := options.ReportPath
+		if reportPath == "" {
+			cwd, _ := os.Getwd()
+			reportPath = filepath.Join(cwd, filepath.FromSlash(defaultCoverageReport))
+		}
+		err = report.WriteJSON(reportPath)
+	}
+	if err != nil {
+		fmt.Fprintf(output, "fixture coverage: %v\n", err)
+		if code == 0 {
+			code = 1
+		}
+		return code
+	}
+	report.WriteSummary(output)
+	if code == 0 && options.FailOnUnused && report.HasUnused() {
+		code = 1
+	}
+	return code
+}
+
+// HasUnused reports whether there are dead fixture files or interactions that were never hit.
+func (r *CoverageReport) HasUnused() bool {
+	return len(r.DeadFiles) > 0 || len(r.NeverHit) > 0
+}
+
+// WriteJSON writes the report to path, creating its directory.
+func (r *CoverageReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding fixture coverage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("creating fixture coverage directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing fixture coverage '%s': %w", path, err)
+	}
+	return nil
+}
+
+// WriteSummary writes a console summary of the report: overall numbers, dead fixture files and never hit interactions.
+func (r *CoverageReport) WriteSummary(w io.Writer) {
+	total, hit := 0, 0
+	for _, f := range r.Files {
+		for _, i := range f.Interactions {
+			total++
+			if i.Matched > 0 {
+				hit++
+			}
+		}
+	}
+	percentage := 100.0
+	if total > 0 {
+		percentage = float64(hit) * 100 / float64(total)
+	}
+	fmt.Fprintf(w, "fixture coverage: %d/%d interactions hit (%.1f%%) in %d pact files\n", hit, total, percentage, len(r.Files))
+	if len(r.DeadFiles) > 0 {
+		fmt.Fprintf(w, "dead fixture files (%d):\n", len(r.DeadFiles))
+		for _, file := range r.DeadFiles {
+			fmt.Fprintf(w, "  %s\n", file)
+		}
+	}
+	if len(r.NeverHit) > 0 {
+		fmt.Fprintf(w, "never hit interactions (%d):\n", len(r.NeverHit))
+		for _, i := range r.NeverHit {
+			state := ""
+			if len(i.ProviderStates) > 0 {
+				state = fmt.Sprintf(" given %q", strings.Join(i.ProviderStates, ", "))
+			}
+			fmt.Fprintf(w, "  %s: %q%s (%s %s), registered %d times\n", i.File, i.Description, state, i.Method, i.Path, i.Registered)
+		}
+	}
+}
+
+// currentCoverage returns the active tracker, locking it, and a function to unlock it; nil if coverage is off.
+func currentCoverage() (*coverageTracker, func()) {
+	coverageMu.Lock()
+	if coverage == nil {
+		coverageMu.Unlock()
+		return nil, nil
+	}
+	return coverage, coverageMu.Unlock
+}
+
+// coverageKey identifies an interaction between a consumer and a provider.
+func coverageKey(consumer, provider string, interaction map[string]interface{}) string {
+	return consumer + "\x00" + provider + "\x00" + interactionKey(interaction)
+}
+
+// trackFixtureRead records that a fixture file, pact or fragment, was read.
+func trackFixtureRead(location string) {
+	tracker, unlock := currentCoverage()
+	if tracker == nil {
+		return
+	}
+	defer unlock()
+	tracker.readFiles[absolutePath(location)] = true
+}
+
+// trackPactLoaded records the interactions of a loaded pact file, before selectors are applied.
+func trackPactLoaded(location string, doc pactDocument) {
+	tracker, unlock := currentCoverage()
+	if tracker == nil {
+		return
+	}
+	defer unlock()
+	file := absolutePath(location)
+	tracker.readFiles[file] = true
+	if _, ok := tracker.pactFiles[file]; ok {
+		return
+	}
+	consumer, provider := participantName(doc, "consumer"), participantName(doc, "provider")
+	keys := []string{}
+	for _, interaction := range documentInteractions(doc, "interactions") {
+		key := coverageKey(consumer, provider, interaction)
+		keys = append(keys, key)
+		if _, ok := tracker.interactions[key]; ok {
+			continue
+		}
+		request, _ := interaction["request"].(map[string]interface{})
+		method, _ := request["method"].(string)
+		path, _ := request["path"].(string)
+		description, _ := interaction["description"].(string)
+		states := providerStateNames(interaction)
+		if len(states) == 0 {
+			states = nil
+		}
+		tracker.interactions[key] = &InteractionCoverage{
+			File:           relativePath(file),
+			Consumer:       consumer,
+			Provider:       provider,
+			Description:    description,
+			ProviderStates: states,
+			Method:         strings.ToUpper(method),
+			Path:           path,
+		}
+	}
+	tracker.pactFiles[file] = keys
+}
+
+// trackRegistered records that a fixture interaction was registered with a mock server.
+func trackRegistered(server *MockServer, interaction interface{}) {
+	tracker, unlock := currentCoverage()
+	if tracker == nil {
+		return
+	}
+	defer unlock()
+	i, _ := interaction.(map[string]interface{})
+	covered, ok := tracker.interactions[coverageKey(server.Consumer, server.Provider, i)]
+	if !ok {
+		return
+	}
+	covered.Registered++
+	tracker.pending[server] = append(tracker.pending[server], covered)
+}
+
+// collectCoverage asks a mock server which of the interactions registered since the last collection were not
+// called, and counts the others as matched. It must be called before the interactions of the server are deleted.
+func collectCoverage(server *MockServer) {
+	tracker, unlock := currentCoverage()
+	if tracker == nil {
+		return
+	}
+	pending := tracker.pending[server]
+	delete(tracker.pending, server)
+	unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	var missing []string
+	if err := server.Verify(); err != nil {
+		var serviceErr *mockServiceError
+		if !errors.As(err, &serviceErr) {
+			return
+		}
+		missing = unmatchedRequests(serviceErr.body)
+	}
+
+	tracker, unlock = currentCoverage()
+	if tracker == nil {
+		return
+	}
+	defer unlock()
+	for _, covered := range pending {
+		// the mock service reports requests by method and path only, so interactions sharing them are
+		// counted as not matched
+		if !containsString(missing, covered.Method+" "+covered.Path) {
+			covered.Matched++
+		}
+	}
+}
+
+// unmatchedRequests returns the "METHOD path" of the missing and incorrect requests listed in the
+// verification failure of the pact mock service.
+func unmatchedRequests(verification string) []string {
+	var requests []string
+	inSection := false
+	for _, line := range strings.Split(verification, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Missing requests:"), strings.HasPrefix(line, "Incorrect requests:"):
+			inSection = true
+		case inSection && strings.HasPrefix(line, "\t"):
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				path, _, _ := strings.Cut(fields[1], "?")
+				requests = append(requests, strings.ToUpper(fields[0])+" "+path)
+			}
+		default:
+			inSection = false
+		}
+	}
+	return requests
+}
+
+func (t *coverageTracker) report(dir string) (*CoverageReport, error) {
+	report := &CoverageReport{Files: []FileCoverage{}, DeadFiles: []string{}, NeverHit: []InteractionCoverage{}}
+	for _, file := range sortedKeys(t.pactFiles) {
+		fileCoverage := FileCoverage{File: relativePath(file), Interactions: []InteractionCoverage{}}
+		for _, key := range t.pactFiles[file] {
+			covered := *t.interactions[key]
+			covered.File = fileCoverage.File
+			fileCoverage.Interactions = append(fileCoverage.Interactions, covered)
+			if covered.Matched == 0 {
+				report.NeverHit = append(report.NeverHit, covered)
+			}
+		}
+		report.Files = append(report.Files, fileCoverage)
+	}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isJSONFile(path) && !isYAMLFile(path) {
+			return nil
+		}
+		if !t.readFiles[absolutePath(path)] {
+			report.DeadFiles = append(report.DeadFiles, relativePath(absolutePath(path)))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("listing fixture files in '%s': %w", dir, err)
+	}
+	sort.Strings(report.DeadFiles)
+	return report, nil
+}
+
+func absolutePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// relativePath returns path relative to the working directory if it is inside it, for readable reports.
+func relativePath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
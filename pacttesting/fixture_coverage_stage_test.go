@@ -0,0 +1,184 @@
+package pacttesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const coverageProvider = "coverageprovider"
+
+type fixtureCoverageStage struct {
+	t       *testing.T
+	mock    *fakeMockService
+	loader  *PactLoader
+	report  *CoverageReport
+	summary string
+	err     error
+}
+
+func FixtureCoverageTest(t *testing.T) (*fixtureCoverageStage, *fixtureCoverageStage, *fixtureCoverageStage) {
+	t.Helper()
+	s := &fixtureCoverageStage{t: t, loader: &PactLoader{Dir: "coveragepacts"}}
+	t.Cleanup(func() {
+		coverageMu.Lock()
+		coverage = nil
+		coverageMu.Unlock()
+		delete(pactServers, coverageProvider+"go-pact-testing")
+		if s.mock != nil {
+			s.mock.server.Close()
+		}
+	})
+	return s, s, s
+}
+
+func (s *fixtureCoverageStage) and() *fixtureCoverageStage {
+	return s
+}
+
+func (s *fixtureCoverageStage) a_mock_service_for_the_provider() *fixtureCoverageStage {
+	s.mock = newFakeMockService()
+	pactServers[coverageProvider+"go-pact-testing"] = &MockServer{
+		BaseURL:  s.mock.server.URL,
+		Consumer: "go-pact-testing",
+		Provider: coverageProvider,
+		Running:  true,
+	}
+	return s
+}
+
+func (s *fixtureCoverageStage) fixture_coverage_is_started() *fixtureCoverageStage {
+	StartFixtureCoverage()
+	return s
+}
+
+func (s *fixtureCoverageStage) a_loader_selecting(selectors ...InteractionSelector) *fixtureCoverageStage {
+	s.loader.Selectors = selectors
+	return s
+}
+
+func (s *fixtureCoverageStage) a_test_calls(paths ...string) *fixtureCoverageStage {
+	err := s.loader.TestWithStubServices([]Pact{coverageProvider}, func() {
+		for _, path := range paths {
+			res, err := http.Get(s.mock.server.URL + path)
+			require.NoError(s.t, err)
+			res.Body.Close()
+		}
+	})
+	require.NoError(s.t, err)
+	return s
+}
+
+func (s *fixtureCoverageStage) fixture_coverage_is_stopped() *fixtureCoverageStage {
+	s.report, s.err = StopFixtureCoverage("coveragepacts")
+	require.NoError(s.t, s.err)
+	var summary bytes.Buffer
+	s.report.WriteSummary(&summary)
+	s.summary = summary.String()
+	return s
+}
+
+func (s *fixtureCoverageStage) the_interaction_was_registered_and_matched(description string, registered, matched int) *fixtureCoverageStage {
+	for _, f := range s.report.Files {
+		for _, i := range f.Interactions {
+			if i.Description == description {
+				assert.Equal(s.t, registered, i.Registered, "registered")
+				assert.Equal(s.t, matched, i.Matched, "matched")
+				return s
+			}
+		}
+	}
+	assert.Failf(s.t, "interaction not in report", "%q", description)
+	return s
+}
+
+func (s *fixtureCoverageStage) the_never_hit_interactions_are(descriptions ...string) *fixtureCoverageStage {
+	actual := make([]string, len(s.report.NeverHit))
+	for i, interaction := range s.report.NeverHit {
+		actual[i] = interaction.Description
+	}
+	assert.ElementsMatch(s.t, descriptions, actual)
+	return s
+}
+
+func (s *fixtureCoverageStage) the_dead_files_are(files ...string) *fixtureCoverageStage {
+	assert.Equal(s.t, files, s.report.DeadFiles)
+	return s
+}
+
+func (s *fixtureCoverageStage) the_summary_contains(text string) *fixtureCoverageStage {
+	assert.Contains(s.t, s.summary, text)
+	return s
+}
+
+func (s *fixtureCoverageStage) the_json_report_can_be_written() *fixtureCoverageStage {
+	path := filepath.Join(s.t.TempDir(), "target", "fixture-coverage.json")
+	require.NoError(s.t, s.report.WriteJSON(path))
+	data, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	var decoded CoverageReport
+	require.NoError(s.t, json.Unmarshal(data, &decoded))
+	assert.Equal(s.t, *s.report, decoded)
+	return s
+}
+
+// fakeMockService imitates the admin API of the pact mock service: it accepts interactions, records the
+// requests it receives and reports registered interactions that were not called on verification.
+type fakeMockService struct {
+	server       *httptest.Server
+	mu           sync.Mutex
+	interactions []string
+	requests     []string
+}
+
+func newFakeMockService() *fakeMockService {
+	f := &fakeMockService{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeMockService) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("X-Pact-Mock-Service") != "true" {
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		return
+	}
+	switch {
+	case r.Method == http.MethodDelete && r.URL.Path == "/interactions":
+		f.interactions, f.requests = nil, nil
+	case r.Method == http.MethodPost && r.URL.Path == "/interactions":
+		var interaction struct {
+			Request struct {
+				Method string `json:"method"`
+				Path   string `json:"path"`
+			} `json:"request"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&interaction); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.interactions = append(f.interactions, interaction.Request.Method+" "+interaction.Request.Path)
+	case r.URL.Path == "/interactions/verification":
+		var missing []string
+		for _, interaction := range f.interactions {
+			if !containsString(f.requests, interaction) {
+				missing = append(missing, "\t"+interaction)
+			}
+		}
+		if len(missing) > 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("Actual interactions do not match expected interactions for mock MockService.\n\n" +
+				"Missing requests:\n" + strings.Join(missing, "\n") + "\n\n\nSee pact/logs/pact.log for details.\n"))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package pacttesting
+
+import "testing"
+
+func TestFixtureCoverage_ReportsMatchedAndNeverHitInteractions(t *testing.T) {
+	given, when, then := FixtureCoverageTest(t)
+
+	given.
+		a_mock_service_for_the_provider().and().
+		fixture_coverage_is_started()
+
+	when.
+		a_test_calls("/v1/used").and().
+		a_test_calls("/v1/used").and().
+		fixture_coverage_is_stopped()
+
+	then.
+		the_interaction_was_registered_and_matched("Request for a used endpoint", 2, 2).and().
+		the_interaction_was_registered_and_matched("Request for an endpoint the consumer no longer calls", 2, 0).and().
+		the_never_hit_interactions_are("Request for an endpoint the consumer no longer calls").and().
+		the_summary_contains("fixture coverage: 1/2 interactions hit (50.0%) in 1 pact files").and().
+		the_summary_contains(`"Request for an endpoint the consumer no longer calls" (GET /v1/unused), registered 2 times`)
+}
+
+func TestFixtureCoverage_ListsDeadFixtureFiles(t *testing.T) {
+	given, when, then := FixtureCoverageTest(t)
+
+	given.
+		a_mock_service_for_the_provider().and().
+		fixture_coverage_is_started()
+
+	when.
+		a_test_calls("/v1/used", "/v1/unused").and().
+		fixture_coverage_is_stopped()
+
+	then.
+		the_never_hit_interactions_are().and().
+		the_dead_files_are("coveragepacts/coverageprovider.dead.json").and().
+		the_summary_contains("dead fixture files (1):\n  coveragepacts/coverageprovider.dead.json")
+}
+
+func TestFixtureCoverage_InteractionsFilteredOutAreNeverRegistered(t *testing.T) {
+	given, when, then := FixtureCoverageTest(t)
+
+	given.
+		a_mock_service_for_the_provider().and().
+		fixture_coverage_is_started().and().
+		a_loader_selecting(WithRequest("GET", "/v1/used"))
+
+	when.
+		a_test_calls("/v1/used").and().
+		fixture_coverage_is_stopped()
+
+	then.
+		the_interaction_was_registered_and_matched("Request for a used endpoint", 1, 1).and().
+		the_interaction_was_registered_and_matched("Request for an endpoint the consumer no longer calls", 0, 0).and().
+		the_json_report_can_be_written()
+}
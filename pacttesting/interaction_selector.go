@@ -0,0 +1,83 @@
+package pacttesting
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// InteractionSelector picks interactions out of a pact file. It is given the interaction as the loader
+// sends it to the mock service, i.e. with variables and matcher shorthand resolved.
+type InteractionSelector func(interaction map[string]interface{}) bool
+
+// WithDescription selects the interaction with the given description.
+func WithDescription(description string) InteractionSelector {
+	return func(interaction map[string]interface{}) bool {
+		actual, _ := interaction["description"].(string)
+		return actual == description
+	}
+}
+
+// WithDescriptionMatching selects interactions whose description matches re.
+func WithDescriptionMatching(re *regexp.Regexp) InteractionSelector {
+	return func(interaction map[string]interface{}) bool {
+		actual, _ := interaction["description"].(string)
+		return re.MatchString(actual)
+	}
+}
+
+// WithProviderState selects interactions that have the named provider state, among others.
+func WithProviderState(name string) InteractionSelector {
+	return func(interaction map[string]interface{}) bool {
+		for _, state := range providerStateNames(interaction) {
+			if state == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithRequest selects interactions whose request has the given method (case-insensitive) and path.
+func WithRequest(method, path string) InteractionSelector {
+	return func(interaction map[string]interface{}) bool {
+		request, _ := interaction["request"].(map[string]interface{})
+		actualMethod, _ := request["method"].(string)
+		actualPath, _ := request["path"].(string)
+		return strings.EqualFold(actualMethod, method) && actualPath == path
+	}
+}
+
+// AllOf selects interactions that all of the given selectors select.
+func AllOf(selectors ...InteractionSelector) InteractionSelector {
+	return func(interaction map[string]interface{}) bool {
+		for _, selector := range selectors {
+			if !selector(interaction) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// selectInteractions keeps the interactions of doc that any of the loader's selectors select.
+// A pact file none of whose interactions are selected is an error, since it usually means a selector is stale.
+func (l *PactLoader) selectInteractions(doc pactDocument, location string) error {
+	if len(l.Selectors) == 0 {
+		return nil
+	}
+	var selected []interface{}
+	for _, interaction := range documentInteractions(doc, "interactions") {
+		for _, selector := range l.Selectors {
+			if selector(interaction) {
+				selected = append(selected, interaction)
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no interactions in pact file '%s' match the selectors", location)
+	}
+	doc["interactions"] = selected
+	return nil
+}
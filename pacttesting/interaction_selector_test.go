@@ -0,0 +1,87 @@
+package pacttesting
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSelector_SelectsByDescription(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts").and().
+		a_loader_selecting(WithDescription("Request for a created organisation built from fragments"))
+
+	when.
+		the_pact_is_loaded("testservicea.get.refs.test")
+
+	then.
+		no_error_is_returned().and().
+		the_pact_has_descriptions("Request for a created organisation built from fragments")
+}
+
+func TestSelector_AnySelectorSelects(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts").and().
+		a_loader_selecting(
+			WithProviderState("organisation exists"),
+			WithRequest("post", "/v1/organisations"),
+		)
+
+	when.
+		the_pact_is_loaded("testservicea.get.refs.test")
+
+	then.
+		no_error_is_returned().and().
+		the_pact_has_descriptions(
+			"Request for an organisation built from fragments",
+			"Request for a created organisation built from fragments",
+		)
+}
+
+func TestSelector_AllOfRequiresEverySelector(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts").and().
+		a_loader_selecting(AllOf(
+			WithDescriptionMatching(regexp.MustCompile(`organisation`)),
+			WithRequest("GET", "/v1/organisations/743d5b63-5e1a-4c89-9c05-0b7e3f5f6a1d"),
+		))
+
+	when.
+		the_pact_is_loaded("testservicea.get.refs.test")
+
+	then.
+		no_error_is_returned().and().
+		the_pact_has_descriptions("Request for an organisation built from fragments")
+}
+
+func TestSelector_SelectingNothingIsAnError(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts").and().
+		a_loader_selecting(WithProviderState("no such state"))
+
+	when.
+		the_pact_is_loaded("testservicea.get.refs.test")
+
+	then.
+		an_error_is_returned_containing("match the selectors")
+}
+
+func TestAcc_verify_pact_with_selected_interactions(t *testing.T) {
+	given, when, then := PactTestingTest(t)
+
+	given.
+		test_service_a_returns_200_for_get_selected_from_shared_file()
+
+	when.
+		test_service_a_is_called()
+
+	then.
+		test_service_a_was_invoked()
+}
@@ -0,0 +1,261 @@
+package pacttesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath identifies a node within a decoded JSON document. Elements are either
+// object keys (string) or array indices (int).
+type jsonPath []interface{}
+
+//nolint:gochecknoglobals // compiled once
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func (p jsonPath) key(k string) jsonPath {
+	return append(p[:len(p):len(p)], k)
+}
+
+func (p jsonPath) index(i int) jsonPath {
+	return append(p[:len(p):len(p)], i)
+}
+
+// String renders the path in the JSONPath notation used by pact matching rules, e.g. $.interactions[0].request.
+func (p jsonPath) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, e := range p {
+		switch v := e.(type) {
+		case int:
+			b.WriteString("[" + strconv.Itoa(v) + "]")
+		case string:
+			if identifierPattern.MatchString(v) {
+				b.WriteString("." + v)
+			} else {
+				b.WriteString("['" + strings.ReplaceAll(v, "'", `\'`) + "']")
+			}
+		}
+	}
+	return b.String()
+}
+
+// jsonPositions maps every node of a JSON document to the byte offset it starts at,
+// so problems found in the decoded document can be reported against the source.
+type jsonPositions struct {
+	data    []byte
+	offsets map[string]int
+}
+
+func indexJSONPositions(data []byte) (*jsonPositions, error) {
+	p := &jsonPositions{data: data, offsets: make(map[string]int)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := p.walk(dec, jsonPath{}); err != nil {
+		return p, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return p, &json.SyntaxError{Offset: dec.InputOffset()}
+	}
+	return p, nil
+}
+
+func (p *jsonPositions) walk(dec *json.Decoder, path jsonPath) error {
+	p.offsets[path.String()] = p.skipSeparators(int(dec.InputOffset()))
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("reading json token: %w", err)
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return fmt.Errorf("reading json key: %w", err)
+			}
+			key, _ := keyTok.(string)
+			if err := p.walk(dec, path.key(key)); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if err := p.walk(dec, path.index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("reading json delimiter: %w", err)
+	}
+	return nil
+}
+
+func (p *jsonPositions) skipSeparators(offset int) int {
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// lookup returns the 1-based line and column of the node at path. When the node does not exist
+// (e.g. a missing mandatory field) the position of its closest existing ancestor is returned.
+func (p *jsonPositions) lookup(path jsonPath) (int, int) {
+	for i := len(path); i >= 0; i-- {
+		if offset, ok := p.offsets[path[:i].String()]; ok {
+			return p.lineColumn(offset)
+		}
+	}
+	return 1, 1
+}
+
+func (p *jsonPositions) lineColumn(offset int) (int, int) {
+	if offset > len(p.data) {
+		offset = len(p.data)
+	}
+	line := 1 + bytes.Count(p.data[:offset], []byte("\n"))
+	column := offset - bytes.LastIndexByte(p.data[:offset], '\n')
+	return line, column
+}
+
+// decodeJSON decodes a JSON document into generic values, keeping numbers as json.Number
+// so that pact documents survive a decode/encode round trip unchanged.
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decoding json: %w", err)
+	}
+	return v, nil
+}
+
+// matcherPathSegment is one step of a matching rule path such as $.body.items[*].id.
+// A nil key together with index -1 denotes a wildcard.
+type matcherPathSegment struct {
+	key   *string
+	index int
+}
+
+func (s matcherPathSegment) wildcard() bool {
+	return s.key == nil && s.index < 0
+}
+
+// parseMatcherPath parses the subset of JSONPath used by pact matching rules:
+// dotted names, bracketed names, array indices and * wildcards.
+func parseMatcherPath(expr string) ([]matcherPathSegment, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("matcher path %q must start with $", expr)
+	}
+	var segments []matcherPathSegment
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("matcher path %q has an empty name", expr)
+			}
+			segments = append(segments, nameSegment(name))
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("matcher path %q has an unterminated [", expr)
+			}
+			inner := rest[1:end]
+			switch {
+			case inner == "*":
+				segments = append(segments, matcherPathSegment{index: -1})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, nameSegment(inner[1:len(inner)-1]))
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("matcher path %q has an invalid index %q", expr, inner)
+				}
+				segments = append(segments, matcherPathSegment{index: i})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("matcher path %q is invalid near %q", expr, rest)
+		}
+	}
+	return segments, nil
+}
+
+func nameSegment(name string) matcherPathSegment {
+	if name == "*" {
+		return matcherPathSegment{index: -1}
+	}
+	return matcherPathSegment{key: &name, index: -1}
+}
+
+// selectMatcherPath returns the nodes of root selected by segments. The second result reports
+// whether a wildcard was applied to an empty collection, in which case an empty selection is legitimate.
+func selectMatcherPath(root interface{}, segments []matcherPathSegment) ([]interface{}, bool) {
+	nodes := []interface{}{root}
+	emptyWildcard := false
+	for _, seg := range segments {
+		var next []interface{}
+		for _, n := range nodes {
+			switch v := n.(type) {
+			case map[string]interface{}:
+				switch {
+				case seg.wildcard():
+					if len(v) == 0 {
+						emptyWildcard = true
+					}
+					keys := sortedKeys(v)
+					for _, k := range keys {
+						next = append(next, v[k])
+					}
+				case seg.key != nil:
+					if child, ok := v[*seg.key]; ok {
+						next = append(next, child)
+					}
+				}
+			case []interface{}:
+				switch {
+				case seg.wildcard():
+					if len(v) == 0 {
+						emptyWildcard = true
+					}
+					next = append(next, v...)
+				case seg.key == nil && seg.index < len(v):
+					next = append(next, v[seg.index])
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes, emptyWildcard
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,252 @@
+package pacttesting
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher shorthand keys accepted in fixture bodies, headers and paths:
+//
+//	{"$like": 123}                                  matches by type, 123 is the example
+//	{"$regex": "^\\d+$", "$example": "42"}          matches the regex, "42" is the example
+//	{"$eachLike": {"id": 1}, "$min": 2}             an array of at least $min (default 1) elements like the template
+const (
+	shorthandLike     = "$like"
+	shorthandRegex    = "$regex"
+	shorthandExample  = "$example"
+	shorthandEachLike = "$eachLike"
+	shorthandMin      = "$min"
+)
+
+// expandMatcherShorthand replaces matcher shorthand in the interactions and messages of doc with their
+// examples and adds the matching rules they stand for. Rules use the layout of the matchingRules already
+// present on the request, response or message; without any they follow the pact specification version
+// in the metadata, defaulting to v3 like the mock service. Shorthand rules replace hand-written rules
+// for the same path.
+func expandMatcherShorthand(doc pactDocument) error {
+	major := documentSpecMajor(doc)
+	v2 := major != 0 && major < 3
+	for i, interaction := range documentInteractions(doc, "interactions") {
+		for _, part := range []string{"request", "response"} {
+			owner, ok := interaction[part].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			location := jsonPath{"interactions", i, part}
+			if err := expandOwnerShorthand(owner, location, v2, map[string]string{"body": "body", "headers": "header", "path": "path"}); err != nil {
+				return err
+			}
+		}
+	}
+	for i, message := range documentInteractions(doc, "messages") {
+		// message matching rules only exist in the v3 layout
+		location := jsonPath{"messages", i}
+		if err := expandOwnerShorthand(message, location, false, map[string]string{"contents": "body"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandOwnerShorthand expands the shorthand in the fields of a request, response or message, mapping
+// each field to its matching rule category.
+func expandOwnerShorthand(owner map[string]interface{}, location jsonPath, v2 bool, categories map[string]string) error {
+	existing, _ := owner["matchingRules"].(map[string]interface{})
+	if len(existing) > 0 {
+		v2 = isV2MatchingRules(existing)
+	}
+
+	e := &shorthandExpander{rules: make(map[string]map[string][]interface{})}
+	for _, field := range sortedKeys(categories) {
+		value, ok := owner[field]
+		if !ok {
+			continue
+		}
+		category := categories[field]
+		expanded, err := e.expand(value, category, nil, location.key(field))
+		if err != nil {
+			return err
+		}
+		owner[field] = expanded
+	}
+	if len(e.rules) == 0 {
+		return nil
+	}
+
+	if existing == nil {
+		existing = make(map[string]interface{})
+	}
+	for _, category := range sortedKeys(e.rules) {
+		for _, path := range sortedKeys(e.rules[category]) {
+			matchers := e.rules[category][path]
+			if v2 {
+				existing[v2RulePath(category, path)] = matchers[0]
+				continue
+			}
+			if category == "path" {
+				existing["path"] = map[string]interface{}{"matchers": matchers}
+				continue
+			}
+			entries, _ := existing[category].(map[string]interface{})
+			if entries == nil {
+				entries = make(map[string]interface{})
+				existing[category] = entries
+			}
+			entries[path] = map[string]interface{}{"matchers": matchers}
+		}
+	}
+	owner["matchingRules"] = existing
+	return nil
+}
+
+// v2RulePath returns the v2 matching rule key for a rule of category at path, e.g. $.body.id or $.headers.Accept.
+func v2RulePath(category, path string) string {
+	switch category {
+	case "body":
+		return "$.body" + strings.TrimPrefix(path, "$")
+	case "header":
+		return "$.headers" + jsonPath{path}.String()[1:]
+	}
+	return "$." + category
+}
+
+type shorthandExpander struct {
+	// rules maps category and path (a body JSONPath or a header name) to matchers.
+	rules map[string]map[string][]interface{}
+}
+
+func (e *shorthandExpander) addRule(category, path string, matcher map[string]interface{}) {
+	if e.rules[category] == nil {
+		e.rules[category] = make(map[string][]interface{})
+	}
+	e.rules[category][path] = []interface{}{matcher}
+}
+
+// expand returns value with all shorthand replaced by examples. segments is the position of value within
+// a body; headers are keyed by name and paths have no position.
+func (e *shorthandExpander) expand(value interface{}, category string, segments []matcherPathSegment, location jsonPath) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isShorthand(v) {
+			return e.expandShorthand(v, category, segments, location)
+		}
+		result := make(map[string]interface{}, len(v))
+		for _, k := range sortedKeys(v) {
+			key := k
+			childCategory, childSegments := category, append(segments[:len(segments):len(segments)], matcherPathSegment{key: &key})
+			if category == "header" {
+				childSegments = []matcherPathSegment{{key: &key}}
+			}
+			expanded, err := e.expand(v[k], childCategory, childSegments, location.key(k))
+			if err != nil {
+				return nil, err
+			}
+			result[k] = expanded
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, child := range v {
+			expanded, err := e.expand(child, category, append(segments[:len(segments):len(segments)], matcherPathSegment{index: i}), location.index(i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = expanded
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func isShorthand(v map[string]interface{}) bool {
+	for _, key := range []string{shorthandLike, shorthandRegex, shorthandEachLike} {
+		if _, ok := v[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *shorthandExpander) expandShorthand(
+	v map[string]interface{}, category string, segments []matcherPathSegment, location jsonPath,
+) (interface{}, error) {
+	allowed := []string{shorthandEachLike, shorthandMin}
+	if _, ok := v[shorthandLike]; ok {
+		allowed = []string{shorthandLike}
+	} else if _, ok := v[shorthandRegex]; ok {
+		allowed = []string{shorthandRegex, shorthandExample}
+	}
+	for _, key := range sortedKeys(v) {
+		if !containsString(allowed, key) {
+			return nil, documentErrorf(location, "%s cannot be combined with %s", key, allowed[0])
+		}
+	}
+	if category == "header" && len(segments) == 0 || category == "path" && len(segments) > 0 {
+		return nil, documentErrorf(location, "matcher shorthand is not supported here")
+	}
+
+	path := formatMatcherPath(segments)
+	if category == "header" {
+		path = *segments[0].key
+	}
+
+	switch allowed[0] {
+	case shorthandLike:
+		example, err := e.expand(v[shorthandLike], category, segments, location.key(shorthandLike))
+		if err != nil {
+			return nil, err
+		}
+		e.addRule(category, path, map[string]interface{}{"match": "type"})
+		return example, nil
+
+	case shorthandRegex:
+		pattern, ok := v[shorthandRegex].(string)
+		if !ok {
+			return nil, documentErrorf(location, "%s must be a string", shorthandRegex)
+		}
+		example, ok := v[shorthandExample].(string)
+		if !ok {
+			return nil, documentErrorf(location, "%s needs a string %s", shorthandRegex, shorthandExample)
+		}
+		// pact regexes are evaluated by the mock service; only check examples Go can understand
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(example) {
+			return nil, documentErrorf(location, "example %q does not match %s %q", example, shorthandRegex, pattern)
+		}
+		e.addRule(category, path, map[string]interface{}{"match": "regex", "regex": pattern})
+		return example, nil
+	}
+
+	if category != "body" {
+		return nil, documentErrorf(location, "%s is only supported in bodies", shorthandEachLike)
+	}
+	minimum := 1
+	if raw, ok := v[shorthandMin]; ok {
+		n, err := strconv.Atoi(fmt.Sprint(raw))
+		if err != nil || n < 1 {
+			return nil, documentErrorf(location, "%s must be a positive integer, got %v", shorthandMin, raw)
+		}
+		minimum = n
+	}
+	element, err := e.expand(v[shorthandEachLike], category,
+		append(segments[:len(segments):len(segments)], matcherPathSegment{index: -1}), location.key(shorthandEachLike))
+	if err != nil {
+		return nil, err
+	}
+	examples := make([]interface{}, minimum)
+	for i := range examples {
+		examples[i] = element
+	}
+	e.addRule(category, path, map[string]interface{}{"match": "type", "min": minimum})
+	return examples, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type matcherShorthandStage struct {
+	t   *testing.T
+	doc pactDocument
+	err error
+}
+
+func MatcherShorthandTest(t *testing.T) (*matcherShorthandStage, *matcherShorthandStage, *matcherShorthandStage) {
+	t.Helper()
+	s := &matcherShorthandStage{t: t}
+	return s, s, s
+}
+
+func (s *matcherShorthandStage) and() *matcherShorthandStage {
+	return s
+}
+
+func (s *matcherShorthandStage) a_pact_with_response(response string) *matcherShorthandStage {
+	doc, err := parsePactDocument([]byte(`{
+		"consumer": {"name": "consumer"},
+		"provider": {"name": "provider"},
+		"interactions": [{"description": "a request", "request": {"method": "GET", "path": "/"}, "response": ` + response + `}]
+	}`))
+	require.NoError(s.t, err)
+	s.doc = doc
+	return s
+}
+
+func (s *matcherShorthandStage) the_pact_specification_version(version string) *matcherShorthandStage {
+	s.doc["metadata"] = map[string]interface{}{"pactSpecification": map[string]interface{}{"version": version}}
+	return s
+}
+
+func (s *matcherShorthandStage) the_shorthand_is_expanded() *matcherShorthandStage {
+	s.err = expandMatcherShorthand(s.doc)
+	return s
+}
+
+func (s *matcherShorthandStage) no_expansion_error_is_returned() *matcherShorthandStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *matcherShorthandStage) the_expansion_fails_with(message string) *matcherShorthandStage {
+	require.Error(s.t, s.err)
+	assert.Equal(s.t, message, s.err.Error())
+	return s
+}
+
+func (s *matcherShorthandStage) the_response_is(expected string) *matcherShorthandStage {
+	response, err := json.Marshal(documentInteractions(s.doc, "interactions")[0]["response"])
+	require.NoError(s.t, err)
+	assert.JSONEq(s.t, expected, string(response))
+	return s
+}
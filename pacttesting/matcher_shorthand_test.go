@@ -0,0 +1,122 @@
+package pacttesting
+
+import "testing"
+
+func TestShorthand_ExpandsToV3MatchingRules(t *testing.T) {
+	given, when, then := MatcherShorthandTest(t)
+
+	given.
+		a_pact_with_response(`{
+			"status": 200,
+			"headers": {"Location": {"$regex": "^/v1/organisations/\\d+$", "$example": "/v1/organisations/1"}},
+			"body": {
+				"id": {"$like": 123},
+				"version": {"$regex": "^v\\d+$", "$example": "v1"},
+				"items": {"$eachLike": {"name": {"$like": "a"}}, "$min": 2}
+			}
+		}`)
+
+	when.
+		the_shorthand_is_expanded()
+
+	then.
+		no_expansion_error_is_returned().and().
+		the_response_is(`{
+			"status": 200,
+			"headers": {"Location": "/v1/organisations/1"},
+			"body": {"id": 123, "version": "v1", "items": [{"name": "a"}, {"name": "a"}]},
+			"matchingRules": {
+				"header": {"Location": {"matchers": [{"match": "regex", "regex": "^/v1/organisations/\\d+$"}]}},
+				"body": {
+					"$.id": {"matchers": [{"match": "type"}]},
+					"$.version": {"matchers": [{"match": "regex", "regex": "^v\\d+$"}]},
+					"$.items": {"matchers": [{"match": "type", "min": 2}]},
+					"$.items[*].name": {"matchers": [{"match": "type"}]}
+				}
+			}
+		}`)
+}
+
+func TestShorthand_FollowsV2Metadata(t *testing.T) {
+	given, when, then := MatcherShorthandTest(t)
+
+	given.
+		a_pact_with_response(`{"status": 200, "body": {"items": {"$eachLike": {"$like": 1}}}}`).and().
+		the_pact_specification_version("2.0.0")
+
+	when.
+		the_shorthand_is_expanded()
+
+	then.
+		no_expansion_error_is_returned().and().
+		the_response_is(`{
+			"status": 200,
+			"body": {"items": [1]},
+			"matchingRules": {
+				"$.body.items": {"match": "type", "min": 1},
+				"$.body.items[*]": {"match": "type"}
+			}
+		}`)
+}
+
+func TestShorthand_KeepsHandWrittenRules(t *testing.T) {
+	given, when, then := MatcherShorthandTest(t)
+
+	given.
+		a_pact_with_response(`{
+			"status": 200,
+			"body": {"id": {"$like": 1}, "name": "a"},
+			"matchingRules": {"$.body.name": {"match": "type"}}
+		}`)
+
+	when.
+		the_shorthand_is_expanded()
+
+	then.
+		no_expansion_error_is_returned().and().
+		the_response_is(`{
+			"status": 200,
+			"body": {"id": 1, "name": "a"},
+			"matchingRules": {"$.body.name": {"match": "type"}, "$.body.id": {"match": "type"}}
+		}`)
+}
+
+func TestShorthand_ExampleMustMatchRegex(t *testing.T) {
+	given, when, then := MatcherShorthandTest(t)
+
+	given.
+		a_pact_with_response(`{"status": 200, "body": {"version": {"$regex": "^v\\d+$", "$example": "1"}}}`)
+
+	when.
+		the_shorthand_is_expanded()
+
+	then.
+		the_expansion_fails_with(`$.interactions[0].response.body.version: example "1" does not match $regex "^v\\d+$"`)
+}
+
+func TestShorthand_UnknownKeysAreAnError(t *testing.T) {
+	given, when, then := MatcherShorthandTest(t)
+
+	given.
+		a_pact_with_response(`{"status": 200, "body": {"id": {"$like": 1, "$min": 1}}}`)
+
+	when.
+		the_shorthand_is_expanded()
+
+	then.
+		the_expansion_fails_with("$.interactions[0].response.body.id: $min cannot be combined with $like")
+}
+
+func TestShorthand_LoadedFixturesAreExpanded(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts")
+
+	when.
+		the_pact_is_loaded("testservicea.get.matchers.test")
+
+	then.
+		no_error_is_returned().and().
+		the_response_body_has("id", "743d5b63-5e1a-4c89-9c05-0b7e3f5f6a1d")
+}
@@ -0,0 +1,211 @@
+package pacttesting
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// matchingRule is a single matching rule in a spec independent form: the v2 layout
+// ("$.body.id": {"match": "type"}) and the v3 layout ("body": {"$.id": {"matchers": [...]}})
+// both normalise to category "body", path "$.id".
+type matchingRule struct {
+	category string
+	// path is the normalised JSONPath for body rules and the lower-cased name for header and
+	// query rules. It is empty for path and status rules.
+	path     string
+	combine  string
+	matchers []map[string]interface{}
+}
+
+func (r matchingRule) key() string {
+	return r.category + " " + r.path
+}
+
+// normaliseMatchingRules returns the matching rules of a request, response or message, keyed by matchingRule.key.
+// Rules that cannot be interpreted are skipped; Lint reports them.
+func normaliseMatchingRules(owner map[string]interface{}) map[string]matchingRule {
+	rules := make(map[string]matchingRule)
+	raw, _ := owner["matchingRules"].(map[string]interface{})
+	if isV2MatchingRules(raw) {
+		for expr, value := range raw {
+			matcher, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			segments, err := parseMatcherPath(expr)
+			if err != nil || len(segments) == 0 || segments[0].key == nil {
+				continue
+			}
+			rule := matchingRule{category: normaliseRuleCategory(*segments[0].key), matchers: []map[string]interface{}{matcher}}
+			switch rule.category {
+			case "body":
+				rule.path = formatMatcherPath(segments[1:])
+			case "header", "query":
+				if len(segments) != 2 || segments[1].key == nil {
+					continue
+				}
+				rule.path = ruleEntryName(rule.category, *segments[1].key)
+			}
+			rules[rule.key()] = rule
+		}
+		return rules
+	}
+
+	for category, value := range raw {
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		category = normaliseRuleCategory(category)
+		if category == "path" || category == "status" {
+			rule := matcherList(entries)
+			rule.category = category
+			rules[rule.key()] = rule
+			continue
+		}
+		for name, entry := range entries {
+			entryMap, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rule := matcherList(entryMap)
+			rule.category = category
+			rule.path = ruleEntryName(category, name)
+			if category == "body" {
+				segments, err := parseMatcherPath(name)
+				if err != nil {
+					continue
+				}
+				rule.path = formatMatcherPath(segments)
+			}
+			rules[rule.key()] = rule
+		}
+	}
+	return rules
+}
+
+func normaliseRuleCategory(category string) string {
+	switch category {
+	case "headers":
+		return "header"
+	case "metaData":
+		return "metadata"
+	}
+	return category
+}
+
+func ruleEntryName(category, name string) string {
+	if category == "header" {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+func matcherList(entry map[string]interface{}) matchingRule {
+	rule := matchingRule{combine: "AND"}
+	if combine, ok := entry["combine"].(string); ok {
+		rule.combine = combine
+	}
+	items, _ := entry["matchers"].([]interface{})
+	for _, item := range items {
+		if matcher, ok := item.(map[string]interface{}); ok {
+			rule.matchers = append(rule.matchers, matcher)
+		}
+	}
+	return rule
+}
+
+// formatMatcherPath renders parsed matcher path segments in a canonical form:
+// $.name for plain names, $['odd name'] otherwise, [n] for indices and [*] for wildcards.
+func formatMatcherPath(segments []matcherPathSegment) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, seg := range segments {
+		switch {
+		case seg.wildcard():
+			b.WriteString("[*]")
+		case seg.key != nil:
+			b.WriteString(jsonPath{*seg.key}.String()[1:])
+		default:
+			b.WriteString("[" + strconv.Itoa(seg.index) + "]")
+		}
+	}
+	return b.String()
+}
+
+// matcherType returns the match type of a single matcher, inferring "regex" and "type" for v2
+// matchers that only carry a regex or min/max.
+func matcherType(matcher map[string]interface{}) string {
+	if match, ok := matcher["match"].(string); ok {
+		return match
+	}
+	if _, ok := matcher["regex"]; ok {
+		return "regex"
+	}
+	return "type"
+}
+
+// matcherStrictness ranks match types from loosest (0) to an exact match against the example (3).
+func matcherStrictness(matchType string) int {
+	switch matchType {
+	case "type", "values", "eachKey", "eachValue", "notEmpty", "arrayContains":
+		return 0
+	case "integer", "decimal", "number", "boolean", "null", "date", "time", "timestamp", "contentType", "semver", "statusCode":
+		return 1
+	case "regex", "include":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// ruleStrictness returns the strictness of a rule: that of its strictest matcher when a value has to satisfy all
+// of them (AND), and that of its loosest matcher when satisfying one is enough (OR).
+func ruleStrictness(rule matchingRule) int {
+	or := strings.EqualFold(rule.combine, "OR")
+	strictness := -1
+	for _, m := range rule.matchers {
+		s := matcherStrictness(matcherType(m))
+		if strictness < 0 || (or && s < strictness) || (!or && s > strictness) {
+			strictness = s
+		}
+	}
+	if strictness < 0 {
+		return matcherStrictness("equality")
+	}
+	return strictness
+}
+
+func sortedRuleKeys(rules map[string]matchingRule) []string {
+	keys := make([]string, 0, len(rules))
+	for k := range rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ruleCovers reports whether a body rule at rulePath applies to the body node at valuePath,
+// either directly, through a wildcard or through an array index.
+func ruleCovers(rulePath, valuePath string) bool {
+	ruleSegments, err := parseMatcherPath(rulePath)
+	if err != nil {
+		return false
+	}
+	valueSegments, err := parseMatcherPath(valuePath)
+	if err != nil || len(ruleSegments) > len(valueSegments) {
+		return false
+	}
+	for i, r := range ruleSegments {
+		v := valueSegments[i]
+		switch {
+		case r.wildcard():
+		case r.key != nil && v.key != nil && *r.key == *v.key:
+		case r.key == nil && v.key == nil && r.index == v.index:
+		default:
+			return false
+		}
+	}
+	return true
+}
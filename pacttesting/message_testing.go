@@ -2,12 +2,12 @@ package pacttesting
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -21,19 +21,24 @@ import (
 
 const providerHTTPScheme = "http://"
 
-func VerifyProviderMessagingPacts(params PactProviderTestParams, messageProducers dsl.MessageHandlers) {
+// VerifyProviderMessagingPacts verifies the message provider against every pact file matching params.Pacts, as a
+// subtest per file, and returns the result for each file.
+func VerifyProviderMessagingPacts(
+	params PactProviderTestParams,
+	messageProducers dsl.MessageHandlers,
+) []*VerificationResult {
 	buildPactClientOnce()
 
-	version, err := getVersion()
+	providerVersion, err := resolveProviderVersion(params)
 	if err != nil {
 		params.Testing.Error(err)
-		return
+		return nil
 	}
 
-	topLevelDir, err := getTopLevelDir()
+	topLevelDir, err := providerRootDir(params)
 	if err != nil {
 		params.Testing.Error(err)
-		return
+		return nil
 	}
 
 	urls, err := filepath.Glob(filepath.Join(topLevelDir, params.Pacts))
@@ -45,60 +50,60 @@ func VerifyProviderMessagingPacts(params PactProviderTestParams, messageProducer
 		params.Testing.Error("No pacts found")
 	}
 
+	var results []*VerificationResult
 	for _, url := range urls {
 		urlparts := strings.SplitAfter(url, "/")
 		filename := urlparts[len(urlparts)-1]
 		params.Testing.Run(filename, func(t *testing.T) {
-			allTestsSucceeded := true
-
 			// perform the verification
+			started := time.Now()
 			responses, err := VerifyMessageProviderRaw(params, dsl.VerifyMessageRequest{
 				PactURLs:        []string{url},
 				MessageHandlers: messageProducers,
+				StateHandlers:   params.StateHandlers,
+				ProviderVersion: providerVersion.version,
 			})
+			result := newVerificationResult(url, started, responses, err)
+			results = append(results, result)
+			if err := markPending(params, topLevelDir, result); err != nil {
+				t.Error(err)
+			}
 
 			// report the results using the test framework
 			for _, response := range responses {
 				for _, example := range response.Examples {
-					testSuccessful := t.Run(example.Description, func(st *testing.T) {
+					t.Run(example.Description, func(st *testing.T) {
 						st.Log(example.FullDescription)
-						if example.Status != "passed" {
+						switch {
+						case example.Status == "passed":
+							st.Log(example.FullDescription)
+						case result.Pending:
+							st.Logf("WARNING: pending pact failed, not failing the test\n%s\n", example.Exception.Message)
+						default:
 							st.Errorf("%s\n", example.Exception.Message)
 							st.Error("Check to ensure that all message expectations have corresponding message handlers")
-						} else {
-							st.Log(example.FullDescription)
 						}
 					})
-					allTestsSucceeded = allTestsSucceeded && testSuccessful
-				}
-
-				if err != nil {
-					t.Errorf("Error verifying message provider: %s", err)
 				}
+			}
+			logPendingResult(t, result)
 
-				t.Run("==> Writing verification.json", func(t *testing.T) {
-					verificationJSON := fmt.Sprintf("{\"success\": %v,\"providerApplicationVersion\": \"%s\"}",
-						err == nil && allTestsSucceeded,
-						version)
-					verificationDir := filepath.Join(topLevelDir, "build", "pact-verifications")
-					_ = os.MkdirAll(verificationDir+"/", 0o744)
-					verificationFile := filepath.Join(verificationDir, filename)
-					if err := os.WriteFile(verificationFile, []byte(verificationJSON), 0o600); err != nil {
-						t.Fatal(err)
-					}
-					outputJSON, err := json.Marshal(response)
-					if err != nil {
-						t.Fatal(err)
-					}
-
-					outFile := filepath.Join(topLevelDir, "build/pact-verifications/", "output-"+filename)
-					if err := os.WriteFile(outFile, outputJSON, 0o600); err != nil {
-						t.Fatal(err)
-					}
-				})
+			if err != nil && result.blocking() {
+				t.Errorf("Error verifying message provider: %s", err)
 			}
+
+			t.Run("==> Writing verification.json", func(t *testing.T) {
+				if err := writeVerification(topLevelDir, filename, providerVersion, result, responses); err != nil {
+					t.Fatal(err)
+				}
+			})
 		})
 	}
+
+	if err := writeVerificationReports(params, topLevelDir, providerVersion, results); err != nil {
+		params.Testing.Error(err)
+	}
+	return results
 }
 
 func messageHandler(messageHandlers dsl.MessageHandlers, stateHandlers dsl.StateHandlers) http.HandlerFunc {
@@ -189,6 +194,11 @@ func VerifyMessageProviderRaw(
 		return emptyResponse, fmt.Errorf("unable to allocate a port for verification: %w", err)
 	}
 
+	headers, err := messageProviderHeaders(params, request)
+	if err != nil {
+		return emptyResponse, err
+	}
+
 	// Construct verifier request
 	verificationRequest := types.VerifyRequest{
 		ProviderBaseURL:            providerHTTPScheme + net.JoinHostPort(getBindAddress(), strconv.Itoa(port)),
@@ -200,12 +210,16 @@ func VerifyMessageProviderRaw(
 		PublishVerificationResults: request.PublishVerificationResults,
 		ProviderVersion:            request.ProviderVersion,
 		ProviderStatesSetupURL:     params.ProviderStateSetupURL,
-		CustomProviderHeaders:      []string{"Authorization: Bearer " + params.AuthToken},
+		CustomProviderHeaders:      headers,
 	}
 
-	mux.HandleFunc("/", messageHandler(request.MessageHandlers, request.StateHandlers))
+	var handler http.Handler = messageHandler(request.MessageHandlers, request.StateHandlers)
+	if params.RequestFilter != nil {
+		handler = params.RequestFilter(handler)
+	}
+	mux.Handle("/", handler)
 
-	ln, err := net.Listen("tcp", fmt.Sprintf("%s:%d", getBindAddress(), port))
+	ln, err := net.Listen("tcp", net.JoinHostPort(getBindAddress(), strconv.Itoa(port)))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -235,6 +249,34 @@ func VerifyMessageProviderRaw(
 	return response, nil
 }
 
+// messageProviderHeaders returns the static headers for the message pacts of request. ConsumerHeaders are looked up
+// by the consumer read from the pact files, which must be local files of a single consumer. InteractionHeaders are
+// an error: every message is produced through the same message handler, so they cannot be told apart by request.
+func messageProviderHeaders(params PactProviderTestParams, request dsl.VerifyMessageRequest) ([]string, error) {
+	if len(params.InteractionHeaders) > 0 {
+		return nil, errors.New("InteractionHeaders cannot be used to verify message pacts")
+	}
+	if len(params.ConsumerHeaders) == 0 {
+		return customProviderHeaders(params, ""), nil
+	}
+	if request.BrokerURL != "" {
+		return nil, errors.New("ConsumerHeaders cannot be used to verify message pacts from a broker")
+	}
+	consumer := ""
+	for i, pactURL := range request.PactURLs {
+		doc, err := readPactDocument(pactURL)
+		if err != nil {
+			return nil, err
+		}
+		name := participantName(doc, "consumer")
+		if i > 0 && name != consumer {
+			return nil, fmt.Errorf("ConsumerHeaders need message pacts of a single consumer, got %s and %s", consumer, name)
+		}
+		consumer = name
+	}
+	return customProviderHeaders(params, consumer), nil
+}
+
 func waitForPort(port int, network string, address string, timeoutDuration time.Duration, message string) error {
 	log.Println("[DEBUG] waiting for port", port, "to become available")
 	timeout := time.After(timeoutDuration)
@@ -245,7 +287,7 @@ func waitForPort(port int, network string, address string, timeoutDuration time.
 			log.Printf("[ERROR] Expected server to start < %s. %s", timeoutDuration, message)
 			return fmt.Errorf("expected server to start < %s. %s", timeoutDuration, message)
 		case <-time.After(50 * time.Millisecond):
-			_, err := net.Dial(network, fmt.Sprintf("%s:%d", address, port))
+			_, err := net.Dial(network, net.JoinHostPort(address, strconv.Itoa(port)))
 			if err == nil {
 				return nil
 			}
@@ -25,6 +25,15 @@ type MockServer struct {
 	Running  bool   `json:"-"`
 }
 
+// mockServiceError is an unsuccessful response of the pact mock service, e.g. a failed verification.
+type mockServiceError struct {
+	body string
+}
+
+func (e *mockServiceError) Error() string {
+	return e.body
+}
+
 // call sends a message to the Pact service
 func (m *MockServer) call(method string, url string, content *string) error {
 	client := &http.Client{}
@@ -51,7 +60,7 @@ func (m *MockServer) call(method string, url string, content *string) error {
 	responseBody, err := io.ReadAll(res.Body)
 	res.Body.Close()
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return errors.New(string(responseBody))
+		return &mockServiceError{body: string(responseBody)}
 	}
 
 	if err != nil {
@@ -83,6 +92,23 @@ func (m *MockServer) Verify() error {
 	return m.call("GET", url, nil)
 }
 
+// WritePact makes the mock service write the pact of the interactions verified so far to its pact directory. It
+// otherwise only writes the pact when it shuts down.
+func (m *MockServer) WritePact() error {
+	url := m.BaseURL + "/pact"
+
+	detailsBytes, err := json.Marshal(map[string]interface{}{
+		"consumer": map[string]string{"name": m.Consumer},
+		"provider": map[string]string{"name": m.Provider},
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling pact details: %w", err)
+	}
+	details := string(detailsBytes)
+
+	return m.call("POST", url, &details)
+}
+
 func (m *MockServer) writePidFile() {
 	bytes, err := json.Marshal(m)
 	if err != nil {
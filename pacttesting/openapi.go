@@ -0,0 +1,558 @@
+package pacttesting
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxRefDepth bounds the number of $ref hops followed in an OpenAPI document, so that cyclic references
+// cannot loop forever.
+const maxRefDepth = 32
+
+// ignoredHeaderParameters are header parameters OpenAPI ignores, since they are described elsewhere in the spec.
+//
+//nolint:gochecknoglobals // constant list
+var ignoredHeaderParameters = []string{"Accept", "Content-Type", "Authorization"}
+
+// OpenAPISpec is a provider's OpenAPI 3 document that pacts can be checked against.
+type OpenAPISpec struct {
+	doc       map[string]interface{}
+	basePaths []string
+	paths     []openAPIPath
+}
+
+// openAPIPath is a path template of the spec, e.g. /organisations/{id}, compiled to match request paths.
+type openAPIPath struct {
+	template string
+	pattern  *regexp.Regexp
+	names    []string
+	// literal is the number of characters outside of parameters; the most literal template wins.
+	literal int
+	item    map[string]interface{}
+}
+
+// OpenAPIViolation is an interaction of a pact that the provider's OpenAPI document does not allow.
+type OpenAPIViolation struct {
+	File           string   `json:"file,omitempty"`
+	Description    string   `json:"description"`
+	ProviderStates []string `json:"providerStates,omitempty"`
+	Method         string   `json:"method"`
+	Path           string   `json:"path"`
+	// Location is the part of the interaction that is wrong, e.g. "request.path", "request.query limit"
+	// or "response.body $.items[0].id".
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func (v OpenAPIViolation) String() string {
+	file := ""
+	if v.File != "" {
+		file = v.File + ": "
+	}
+	state := ""
+	if len(v.ProviderStates) > 0 {
+		state = fmt.Sprintf(" given %q", strings.Join(v.ProviderStates, ", "))
+	}
+	return fmt.Sprintf("%s%q%s (%s %s): %s: %s", file, v.Description, state, v.Method, v.Path, v.Location, v.Message)
+}
+
+// LoadOpenAPISpec reads an OpenAPI 3 document written in JSON or YAML.
+func LoadOpenAPISpec(path string) (*OpenAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading OpenAPI document '%s': %w", path, err)
+	}
+	if isYAMLFile(path) {
+		if data, err = ConvertYAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("converting OpenAPI document '%s': %w", path, err)
+		}
+	}
+	spec, err := ParseOpenAPISpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document '%s': %w", path, err)
+	}
+	return spec, nil
+}
+
+// ParseOpenAPISpec parses an OpenAPI 3 document in JSON. Only references within the document are followed.
+func ParseOpenAPISpec(data []byte) (*OpenAPISpec, error) {
+	decoded, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("an OpenAPI document must be an object")
+	}
+	if version, _ := doc["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3 documents are supported, got openapi version %q", version)
+	}
+
+	spec := &OpenAPISpec{doc: doc}
+	servers, _ := doc["servers"].([]interface{})
+	for _, s := range servers {
+		server, _ := s.(map[string]interface{})
+		raw, _ := server["url"].(string)
+		if u, err := url.Parse(raw); err == nil {
+			if base := strings.TrimSuffix(u.Path, "/"); base != "" {
+				spec.basePaths = append(spec.basePaths, base)
+			}
+		}
+	}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for _, template := range sortedKeys(paths) {
+		item, ok := spec.resolve(paths[template]).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		compiled, err := compilePathTemplate(template)
+		if err != nil {
+			return nil, err
+		}
+		compiled.item = item
+		spec.paths = append(spec.paths, compiled)
+	}
+	return spec, nil
+}
+
+//nolint:gochecknoglobals // compiled once
+var pathParameterPattern = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+func compilePathTemplate(template string) (openAPIPath, error) {
+	compiled := openAPIPath{template: template}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, m := range pathParameterPattern.FindAllStringSubmatchIndex(template, -1) {
+		literal := template[last:m[0]]
+		pattern.WriteString(regexp.QuoteMeta(literal))
+		pattern.WriteString("([^/]+)")
+		compiled.literal += len(literal)
+		compiled.names = append(compiled.names, template[m[2]:m[3]])
+		last = m[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]) + "$")
+	compiled.literal += len(template) - last
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return compiled, fmt.Errorf("path template %q: %w", template, err)
+	}
+	compiled.pattern = re
+	return compiled, nil
+}
+
+// resolve follows local $ref objects such as {"$ref": "#/components/schemas/Organisation"}. References that
+// cannot be followed resolve to nil.
+func (s *OpenAPISpec) resolve(node interface{}) interface{} {
+	for i := 0; i < maxRefDepth; i++ {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return node
+		}
+		if !strings.HasPrefix(ref, "#") {
+			return nil
+		}
+		target, err := resolveJSONPointer(s.doc, strings.TrimPrefix(ref, "#"))
+		if err != nil {
+			return nil
+		}
+		node = target
+	}
+	return nil
+}
+
+// findPath returns the path template matching a request path, with or without a server base path, and the
+// values of its parameters.
+func (s *OpenAPISpec) findPath(requestPath string) (*openAPIPath, map[string]string) {
+	candidates := []string{requestPath}
+	for _, base := range s.basePaths {
+		if strings.HasPrefix(requestPath, base+"/") {
+			candidates = append(candidates, strings.TrimPrefix(requestPath, base))
+		}
+	}
+	var best *openAPIPath
+	var values map[string]string
+	for _, candidate := range candidates {
+		for i := range s.paths {
+			p := &s.paths[i]
+			m := p.pattern.FindStringSubmatch(candidate)
+			if m == nil || best != nil && best.literal >= p.literal {
+				continue
+			}
+			best = p
+			values = make(map[string]string, len(p.names))
+			for j, name := range p.names {
+				value, err := url.PathUnescape(m[j+1])
+				if err != nil {
+					value = m[j+1]
+				}
+				values[name] = value
+			}
+		}
+	}
+	return best, values
+}
+
+// ValidatePactFiles reads every given pact file and checks it against the spec as it is, e.g. pacts written by the
+// mock service or downloaded from a broker. Use ValidateFixtureFiles or ValidateFixtures for fixtures.
+func (s *OpenAPISpec) ValidatePactFiles(paths ...string) ([]OpenAPIViolation, error) {
+	var violations []OpenAPIViolation
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading pact file '%s': %w", path, err)
+		}
+		found, err := s.validatePact(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("checking pact file '%s': %w", path, err)
+		}
+		violations = append(violations, found...)
+	}
+	return violations, nil
+}
+
+// ValidateFixtureFiles checks every given fixture file like ValidateFixtures, with a loader reading from the
+// directory of the file. Fixtures with variables need ValidateFixtures, which can be given their values.
+func (s *OpenAPISpec) ValidateFixtureFiles(paths ...string) ([]OpenAPIViolation, error) {
+	var violations []OpenAPIViolation
+	for _, path := range paths {
+		found, err := s.ValidateFixtures(&PactLoader{Dir: filepath.Dir(path)}, filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+	return violations, nil
+}
+
+// ValidateFixtures loads the named fixtures with loader, as AddPact does, and checks them against the spec in the
+// form they are sent to the mock service: converted from YAML, with fragment references, variables and matcher
+// shorthand resolved. Selectors of loader are not applied.
+func (s *OpenAPISpec) ValidateFixtures(loader *PactLoader, names ...string) ([]OpenAPIViolation, error) {
+	var violations []OpenAPIViolation
+	for _, name := range names {
+		doc, location, err := loader.resolvePactDocument(name)
+		if err != nil {
+			return nil, fmt.Errorf("checking pact fixture '%s': %w", name, err)
+		}
+		violations = append(violations, s.validateDocument(location, doc)...)
+	}
+	return violations, nil
+}
+
+// ValidatePact checks every HTTP interaction of a pact against the spec: its request must be a declared
+// operation with valid parameters and body, and its expected response must be declared with a compatible body.
+func (s *OpenAPISpec) ValidatePact(data []byte) ([]OpenAPIViolation, error) {
+	return s.validatePact("", data)
+}
+
+// validatePact checks a pact read from file as it is.
+func (s *OpenAPISpec) validatePact(file string, data []byte) ([]OpenAPIViolation, error) {
+	decoded, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("a pact must be an object")
+	}
+	return s.validateDocument(file, doc), nil
+}
+
+func (s *OpenAPISpec) validateDocument(file string, doc pactDocument) []OpenAPIViolation {
+	var violations []OpenAPIViolation
+	for _, interaction := range documentInteractions(doc, "interactions") {
+		request, _ := interaction["request"].(map[string]interface{})
+		method, _ := request["method"].(string)
+		requestPath, _ := request["path"].(string)
+		description, _ := interaction["description"].(string)
+		report := func(location, format string, args ...interface{}) {
+			violations = append(violations, OpenAPIViolation{
+				File:           file,
+				Description:    description,
+				ProviderStates: providerStateNames(interaction),
+				Method:         strings.ToUpper(method),
+				Path:           requestPath,
+				Location:       location,
+				Message:        fmt.Sprintf(format, args...),
+			})
+		}
+		s.validateInteraction(interaction, report)
+	}
+	return violations
+}
+
+// violationReporter records a violation of the interaction being checked.
+type violationReporter func(location, format string, args ...interface{})
+
+func (s *OpenAPISpec) validateInteraction(interaction map[string]interface{}, report violationReporter) {
+	request, _ := interaction["request"].(map[string]interface{})
+	response, _ := interaction["response"].(map[string]interface{})
+	method, _ := request["method"].(string)
+	requestPath, _ := request["path"].(string)
+
+	path, pathValues := s.findPath(requestPath)
+	if path == nil {
+		report("request.path", "no path of the OpenAPI document matches %s", requestPath)
+		return
+	}
+	operation, ok := s.resolve(path.item[strings.ToLower(method)]).(map[string]interface{})
+	if !ok {
+		report("request.method", "%s does not declare %s", path.template, strings.ToUpper(method))
+		return
+	}
+
+	s.validateParameters(path, operation, pathValues, request, report)
+	s.validateRequestBody(operation, request, report)
+	s.validateResponse(operation, response, report)
+}
+
+// operationParameters merges the parameters of a path item and an operation, the operation's taking precedence.
+func (s *OpenAPISpec) operationParameters(path *openAPIPath, operation map[string]interface{}) []map[string]interface{} {
+	byKey := make(map[string]map[string]interface{})
+	var keys []string
+	for _, owner := range []map[string]interface{}{path.item, operation} {
+		list, _ := owner["parameters"].([]interface{})
+		for _, p := range list {
+			parameter, ok := s.resolve(p).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := parameter["name"].(string)
+			in, _ := parameter["in"].(string)
+			key := in + " " + name
+			if in == "header" {
+				key = in + " " + strings.ToLower(name)
+			}
+			if _, seen := byKey[key]; !seen {
+				keys = append(keys, key)
+			}
+			byKey[key] = parameter
+		}
+	}
+	parameters := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		parameters = append(parameters, byKey[key])
+	}
+	return parameters
+}
+
+func (s *OpenAPISpec) validateParameters(
+	path *openAPIPath,
+	operation map[string]interface{},
+	pathValues map[string]string,
+	request map[string]interface{},
+	report violationReporter,
+) {
+	query := queryValues(request["query"])
+	headers := headerValues(request["headers"])
+	declaredQuery := make(map[string]bool)
+
+	for _, parameter := range s.operationParameters(path, operation) {
+		name, _ := parameter["name"].(string)
+		in, _ := parameter["in"].(string)
+		required, _ := parameter["required"].(bool)
+		var values []string
+		present := false
+		switch in {
+		case "path":
+			var value string
+			value, present = pathValues[name]
+			values = []string{value}
+		case "query":
+			declaredQuery[name] = true
+			var list interface{}
+			list, present = query[name]
+			values = parameterStrings(list)
+		case "header":
+			if containsFold(ignoredHeaderParameters, name) {
+				continue
+			}
+			var value interface{}
+			value, present = headers[strings.ToLower(name)]
+			values = parameterStrings(value)
+		default:
+			continue
+		}
+		location := "request." + in + " " + name
+		if !present {
+			if required {
+				report(location, "required %s parameter %q is missing", in, name)
+			}
+			continue
+		}
+		schema := parameter["schema"]
+		if types := schemaTypes(s.resolveSchema(schema)); containsString(types, "array") && len(values) > 1 {
+			list := make([]interface{}, 0, len(values))
+			for _, value := range values {
+				list = append(list, s.coerceParameter(s.resolveSchema(schema)["items"], value))
+			}
+			s.checkSchema(schema, list, jsonPath{}, schemaViolations(report, location))
+			continue
+		}
+		for _, value := range values {
+			s.checkSchema(schema, s.coerceParameter(schema, value), jsonPath{}, schemaViolations(report, location))
+		}
+	}
+
+	for _, name := range sortedKeys(query) {
+		if !declaredQuery[name] {
+			report("request.query "+name, "query parameter %q is not declared", name)
+		}
+	}
+}
+
+func (s *OpenAPISpec) validateRequestBody(operation map[string]interface{}, request map[string]interface{}, report violationReporter) {
+	body, hasBody := request["body"]
+	requestBody, declared := s.resolve(operation["requestBody"]).(map[string]interface{})
+	switch {
+	case !declared && hasBody:
+		report("request.body", "the operation does not accept a request body")
+		return
+	case !hasBody:
+		if required, _ := requestBody["required"].(bool); required {
+			report("request.body", "the operation requires a request body")
+		}
+		return
+	}
+	s.validateContent(requestBody, request, body, "request.body", report)
+}
+
+func (s *OpenAPISpec) validateResponse(operation map[string]interface{}, response map[string]interface{}, report violationReporter) {
+	status, ok := numericValue(response["status"])
+	if !ok {
+		return
+	}
+	responses, _ := operation["responses"].(map[string]interface{})
+	code := strconv.Itoa(int(status))
+	declared, ok := s.resolve(responseFor(responses, code)).(map[string]interface{})
+	if !ok {
+		report("response.status", "status %s is not declared", code)
+		return
+	}
+
+	declaredHeaders, _ := declared["headers"].(map[string]interface{})
+	for _, name := range sortedKeys(headerValues(response["headers"])) {
+		if name == "content-type" {
+			continue
+		}
+		header, ok := declaredHeaders[headerName(declaredHeaders, name)]
+		if !ok {
+			report("response.headers "+name, "response header %q is not declared for status %s", name, code)
+			continue
+		}
+		schema, _ := s.resolve(header).(map[string]interface{})
+		for _, value := range parameterStrings(headerValues(response["headers"])[name]) {
+			s.checkSchema(schema["schema"], s.coerceParameter(schema["schema"], value), jsonPath{},
+				schemaViolations(report, "response.headers "+name))
+		}
+	}
+
+	if body, hasBody := response["body"]; hasBody {
+		s.validateContent(declared, response, body, "response.body", report)
+	}
+}
+
+// responseFor returns the response declared for a status code: the exact code, its range such as 2XX, or default.
+func responseFor(responses map[string]interface{}, code string) interface{} {
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := responses[key]; ok {
+			return response
+		}
+	}
+	return nil
+}
+
+// headerName returns the key of headers that equals name ignoring case.
+func headerName(headers map[string]interface{}, name string) string {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+// validateContent checks a request or response body against the schema of the matching media type of a
+// requestBody or response object. Only JSON bodies are checked against schemas.
+func (s *OpenAPISpec) validateContent(declared, owner map[string]interface{}, body interface{}, location string, report violationReporter) {
+	content, _ := declared["content"].(map[string]interface{})
+	if len(content) == 0 {
+		report(location, "no body is declared")
+		return
+	}
+	contentType, _ := headerValues(owner["headers"])["content-type"].(string)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	key, ok := mediaTypeFor(content, mediaType)
+	if !ok {
+		report(location, "media type %q is not declared, expected one of %s", mediaType, strings.Join(sortedKeys(content), ", "))
+		return
+	}
+	if !isJSONMediaType(key) && !isJSONMediaType(mediaType) {
+		return
+	}
+	media, _ := s.resolve(content[key]).(map[string]interface{})
+	s.checkSchema(media["schema"], body, jsonPath{}, func(p jsonPath, format string, args ...interface{}) {
+		report(location+" "+p.String(), format, args...)
+	})
+}
+
+// mediaTypeFor returns the content key matching a media type, preferring exact matches over wildcards.
+// Without a media type, the first JSON media type is used.
+func mediaTypeFor(content map[string]interface{}, mediaType string) (string, bool) {
+	keys := sortedKeys(content)
+	if mediaType == "" {
+		for _, key := range keys {
+			if isJSONMediaType(key) {
+				return key, true
+			}
+		}
+		return keys[0], true
+	}
+	candidates := []string{mediaType, strings.SplitN(mediaType, "/", 2)[0] + "/*", "*/*"}
+	for _, candidate := range candidates {
+		for _, key := range keys {
+			if parsed, _, err := mime.ParseMediaType(key); err == nil && parsed == candidate {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// parameterStrings returns the values of a query parameter or header, which pacts hold as a string or list.
+func parameterStrings(v interface{}) []string {
+	switch value := v.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	}
+	return nil
+}
+
+// schemaViolations reports schema problems of a parameter or header at location.
+func schemaViolations(report violationReporter, location string) schemaReporter {
+	return func(_ jsonPath, format string, args ...interface{}) {
+		report(location, format, args...)
+	}
+}
@@ -0,0 +1,502 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxExampleDepth bounds the nesting of generated examples, so that recursive schemas terminate.
+const maxExampleDepth = 8
+
+// openAPIMethods are the operations a path item may declare, in the order they are generated.
+//
+//nolint:gochecknoglobals // constant list
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// formatPatterns are the regexes generated fixtures match string formats with, and formatExamples their examples.
+//
+//nolint:gochecknoglobals // constant lookup table
+var (
+	formatPatterns = map[string]string{
+		"date-time": `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`,
+		"date":      `^\d{4}-\d{2}-\d{2}$`,
+		"uuid":      uuidPattern.String(),
+	}
+	formatExamples = map[string]string{
+		"date-time": "2020-01-01T00:00:00Z",
+		"date":      "2020-01-01",
+		"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		"email":     "user@example.com",
+		"uri":       "https://example.com",
+	}
+)
+
+//nolint:gochecknoglobals // compiled once
+var repeatedDotsPattern = regexp.MustCompile(`\.+`)
+
+// OpenAPIOperation identifies an operation of an OpenAPI document.
+type OpenAPIOperation struct {
+	// ID is the operationId, if the document declares one.
+	ID     string
+	Method string
+	// Path is the path template, e.g. /organisations/{id}.
+	Path string
+	Tags []string
+}
+
+// name returns the operationId, or a name derived from the method and path.
+func (o OpenAPIOperation) name() string {
+	if o.ID != "" {
+		return o.ID
+	}
+	return strings.ToLower(o.Method) + " " + o.Path
+}
+
+// OperationSelector picks the operations GeneratePacts generates fixtures for.
+type OperationSelector func(operation OpenAPIOperation) bool
+
+// WithOperationID selects the operations with one of the given operationIds.
+func WithOperationID(ids ...string) OperationSelector {
+	return func(operation OpenAPIOperation) bool {
+		return containsString(ids, operation.ID)
+	}
+}
+
+// WithTag selects the operations tagged with tag.
+func WithTag(tag string) OperationSelector {
+	return func(operation OpenAPIOperation) bool {
+		return containsString(operation.Tags, tag)
+	}
+}
+
+// WithPathPrefix selects the operations whose path template starts with prefix.
+func WithPathPrefix(prefix string) OperationSelector {
+	return func(operation OpenAPIOperation) bool {
+		return strings.HasPrefix(operation.Path, prefix)
+	}
+}
+
+// GenerateOptions configures GeneratePacts.
+type GenerateOptions struct {
+	Consumer string
+	// Provider defaults to the title of the OpenAPI document.
+	Provider string
+	// Selectors pick the operations to generate fixtures for; an operation any of them selects is generated.
+	// Without selectors, all operations are generated.
+	Selectors []OperationSelector
+}
+
+// GeneratedPact is a pact fixture with a single interaction, generated for one response of an operation.
+type GeneratedPact struct {
+	Consumer  string
+	Provider  string
+	Operation OpenAPIOperation
+	Status    int
+	document  pactDocument
+}
+
+// FileName returns the name of the fixture, <provider>.<operation>.<status>.json.
+func (g *GeneratedPact) FileName() string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if r == '{' || r == '}' || r == ' ' || r == '/' {
+			return '.'
+		}
+		return r
+	}, g.Operation.name()), ".")
+	name = repeatedDotsPattern.ReplaceAllString(name, ".")
+	return sanitize(fmt.Sprintf("%s.%s.%d.json", g.Provider, name, g.Status))
+}
+
+// JSON returns the fixture content in canonical form.
+func (g *GeneratedPact) JSON() ([]byte, error) {
+	data, err := marshalPactDocument(g.document)
+	if err != nil {
+		return nil, err
+	}
+	return FormatPact(data)
+}
+
+// GeneratePacts generates a pact fixture for every response of every selected operation, ready to be loaded
+// with AddPact or IntegrationTest. Requests carry the required parameters and, like responses, example bodies
+// taken from the document or derived from the schemas. Bodies use matcher shorthand, so that their fields are
+// matched by type, or by regex for enums and string formats. Response ranges such as 2XX are generated with
+// the first status of the range; default responses are skipped since they have no status.
+func (s *OpenAPISpec) GeneratePacts(options GenerateOptions) ([]*GeneratedPact, error) {
+	provider := options.Provider
+	if provider == "" {
+		info, _ := s.doc["info"].(map[string]interface{})
+		provider, _ = info["title"].(string)
+	}
+	if provider == "" {
+		return nil, fmt.Errorf("a provider name is needed, since the OpenAPI document has no title")
+	}
+
+	var generated []*GeneratedPact
+	for i := range s.paths {
+		path := &s.paths[i]
+		for _, method := range openAPIMethods {
+			operation, ok := s.resolve(path.item[method]).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op := OpenAPIOperation{Method: strings.ToUpper(method), Path: path.template}
+			op.ID, _ = operation["operationId"].(string)
+			tags, _ := operation["tags"].([]interface{})
+			for _, tag := range tags {
+				if name, ok := tag.(string); ok {
+					op.Tags = append(op.Tags, name)
+				}
+			}
+			if !selectOperation(op, options.Selectors) {
+				continue
+			}
+
+			request := s.exampleRequest(path, operation, op)
+			responses, _ := operation["responses"].(map[string]interface{})
+			for _, code := range sortedKeys(responses) {
+				status, ok := exampleStatus(code)
+				if !ok {
+					continue
+				}
+				response, _ := s.resolve(responses[code]).(map[string]interface{})
+				generated = append(generated, &GeneratedPact{
+					Consumer:  options.Consumer,
+					Provider:  provider,
+					Operation: op,
+					Status:    status,
+					document:  s.examplePact(options.Consumer, provider, op, status, request, response),
+				})
+			}
+		}
+	}
+	return generated, nil
+}
+
+// WriteGeneratedPacts writes each generated pact to outputDirPath using GeneratedPact.FileName.
+func WriteGeneratedPacts(generated []*GeneratedPact, outputDirPath string) error {
+	if err := os.MkdirAll(outputDirPath, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't create output directory '%s': %w", outputDirPath, err)
+	}
+	for _, g := range generated {
+		content, err := g.JSON()
+		if err != nil {
+			return fmt.Errorf("generating pact for %s %s: %w", g.Operation.Method, g.Operation.Path, err)
+		}
+		path := filepath.Join(outputDirPath, g.FileName())
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return fmt.Errorf("couldn't write generated pact file '%s': %w", path, err)
+		}
+	}
+	return nil
+}
+
+func selectOperation(operation OpenAPIOperation, selectors []OperationSelector) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+	for _, selector := range selectors {
+		if selector(operation) {
+			return true
+		}
+	}
+	return false
+}
+
+// exampleStatus returns the status generated for a response code: the code itself, or the first status of a
+// range such as 4XX.
+func exampleStatus(code string) (int, bool) {
+	if len(code) == 3 && strings.EqualFold(code[1:], "XX") {
+		code = code[:1] + "00"
+	}
+	status, err := strconv.Atoi(code)
+	return status, err == nil && status >= 100 && status < 600
+}
+
+func (s *OpenAPISpec) examplePact(
+	consumer, provider string, op OpenAPIOperation, status int, request, response map[string]interface{},
+) pactDocument {
+	description := fmt.Sprintf("%s returns %d", op.name(), status)
+	if op.ID == "" {
+		description = fmt.Sprintf("%s %s returns %d", op.Method, op.Path, status)
+	}
+	expected := map[string]interface{}{"status": status}
+	content, _ := response["content"].(map[string]interface{})
+	if len(content) > 0 {
+		mediaType, _ := mediaTypeFor(content, "")
+		media, _ := s.resolve(content[mediaType]).(map[string]interface{})
+		expected["headers"] = map[string]interface{}{
+			"Content-Type": map[string]interface{}{
+				shorthandRegex:   "^" + regexp.QuoteMeta(mediaType),
+				shorthandExample: mediaType,
+			},
+		}
+		if body := s.exampleBody(media); body != nil {
+			expected["body"] = s.exampleMatchers(media["schema"], body, 0)
+		}
+	}
+	return pactDocument{
+		"consumer": map[string]interface{}{"name": consumer},
+		"provider": map[string]interface{}{"name": provider},
+		"interactions": []interface{}{map[string]interface{}{
+			"description": description,
+			// each status needs its own provider state, as the fixtures of an operation send the same request
+			"providerStates": []interface{}{map[string]interface{}{"name": description}},
+			"request":        request,
+			"response":       expected,
+		}},
+		"metadata": map[string]interface{}{"pactSpecification": map[string]interface{}{"version": "3.0.0"}},
+	}
+}
+
+// exampleRequest returns a request for an operation with all required parameters and, if the operation
+// accepts one, a body.
+func (s *OpenAPISpec) exampleRequest(path *openAPIPath, operation map[string]interface{}, op OpenAPIOperation) map[string]interface{} {
+	base := ""
+	if len(s.basePaths) > 0 {
+		base = s.basePaths[0]
+	}
+	requestPath := base + path.template
+	query := make(map[string]interface{})
+	headers := make(map[string]interface{})
+	for _, parameter := range s.operationParameters(path, operation) {
+		name, _ := parameter["name"].(string)
+		in, _ := parameter["in"].(string)
+		required, _ := parameter["required"].(bool)
+		value := "value"
+		if example := s.exampleValue(parameter, 0); example != nil {
+			value = fmt.Sprint(example)
+		}
+		switch {
+		case in == "path":
+			requestPath = strings.ReplaceAll(requestPath, "{"+name+"}", value)
+		case in == "query" && required:
+			query[name] = []interface{}{value}
+		case in == "header" && required && !containsFold(ignoredHeaderParameters, name):
+			headers[name] = value
+		}
+	}
+
+	request := map[string]interface{}{"method": op.Method, "path": requestPath}
+	if requestPath != base+path.template {
+		// the consumer is free to use any values for path parameters
+		request["path"] = map[string]interface{}{
+			shorthandRegex:   "^" + regexp.QuoteMeta(base) + strings.TrimPrefix(path.pattern.String(), "^"),
+			shorthandExample: requestPath,
+		}
+	}
+	if len(query) > 0 {
+		request["query"] = query
+	}
+	requestBody, _ := s.resolve(operation["requestBody"]).(map[string]interface{})
+	if content, _ := requestBody["content"].(map[string]interface{}); len(content) > 0 {
+		mediaType, _ := mediaTypeFor(content, "")
+		media, _ := s.resolve(content[mediaType]).(map[string]interface{})
+		headers["Content-Type"] = mediaType
+		if body := s.exampleBody(media); body != nil {
+			request["body"] = s.exampleMatchers(media["schema"], body, 0)
+		}
+	}
+	if len(headers) > 0 {
+		request["headers"] = headers
+	}
+	return request
+}
+
+// exampleBody returns the example of a media type object, or one derived from its schema.
+func (s *OpenAPISpec) exampleBody(media map[string]interface{}) interface{} {
+	if example, ok := media["example"]; ok {
+		return example
+	}
+	examples, _ := media["examples"].(map[string]interface{})
+	for _, name := range sortedKeys(examples) {
+		if example, ok := s.resolve(examples[name]).(map[string]interface{}); ok {
+			if value, ok := example["value"]; ok {
+				return value
+			}
+		}
+	}
+	return s.exampleValue(map[string]interface{}{"schema": media["schema"]}, 0)
+}
+
+// exampleValue returns the example of a parameter or an object holding a schema, or one derived from the schema.
+func (s *OpenAPISpec) exampleValue(owner map[string]interface{}, depth int) interface{} {
+	if example, ok := owner["example"]; ok {
+		return example
+	}
+	return s.exampleFor(owner["schema"], depth)
+}
+
+// exampleFor derives an example value from a schema, preferring the examples and defaults it declares.
+// Objects get their required properties, or all properties if none are required.
+func (s *OpenAPISpec) exampleFor(schemaNode interface{}, depth int) interface{} {
+	schema := s.resolveSchema(schemaNode)
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "const", "default"} {
+		if value, ok := schema[key]; ok {
+			return value
+		}
+	}
+	if examples, ok := schema["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0]
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if allOf := schemaList(schema["allOf"]); len(allOf) > 0 {
+		merged := make(map[string]interface{})
+		for _, sub := range allOf {
+			if object, ok := s.exampleFor(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range object {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if alternatives := schemaList(schema[keyword]); len(alternatives) > 0 {
+			return s.exampleFor(alternatives[0], depth+1)
+		}
+	}
+
+	switch exampleType(schema) {
+	case "string":
+		format, _ := schema["format"].(string)
+		if example, ok := formatExamples[format]; ok {
+			return example
+		}
+		example := "string"
+		if minimum, ok := numericValue(schema["minLength"]); ok && float64(len(example)) < minimum {
+			example += strings.Repeat("x", int(minimum)-len(example))
+		}
+		return example
+	case "integer", "number":
+		value := 1.0
+		if minimum, ok := numericValue(schema["minimum"]); ok && value < minimum {
+			value = minimum
+		}
+		if maximum, ok := numericValue(schema["maximum"]); ok && value > maximum {
+			value = maximum
+		}
+		return json.Number(strconv.FormatFloat(value, 'f', -1, 64))
+	case "boolean":
+		return true
+	case "array":
+		item := s.exampleFor(schema["items"], depth+1)
+		count := 1
+		if minimum, ok := numericValue(schema["minItems"]); ok && minimum > 1 {
+			count = int(minimum)
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = item
+		}
+		return items
+	case "object":
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := sortedKeys(properties)
+		if required, _ := schema["required"].([]interface{}); len(required) > 0 {
+			names = names[:0]
+			for _, name := range required {
+				if key, ok := name.(string); ok {
+					names = append(names, key)
+				}
+			}
+		}
+		object := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			object[name] = s.exampleFor(properties[name], depth+1)
+		}
+		return object
+	}
+	return nil
+}
+
+// exampleType returns the non-null type of a schema, inferring it from the keywords used if it is not declared.
+func exampleType(schema map[string]interface{}) string {
+	for _, t := range schemaTypes(schema) {
+		if t != "null" {
+			return t
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// exampleMatchers wraps an example body in matcher shorthand derived from its schema: arrays match each element,
+// enums, string formats and patterns match by regex and other values match by type.
+func (s *OpenAPISpec) exampleMatchers(schemaNode interface{}, example interface{}, depth int) interface{} {
+	schema := s.resolveSchema(schemaNode)
+	if schema == nil || depth > maxExampleDepth {
+		return example
+	}
+	if allOf := schemaList(schema["allOf"]); len(allOf) > 0 {
+		// the properties of all parts describe the fields of the merged example
+		properties := make(map[string]interface{})
+		for _, sub := range allOf {
+			subProperties, _ := s.resolveSchema(sub)["properties"].(map[string]interface{})
+			for k, v := range subProperties {
+				properties[k] = v
+			}
+		}
+		schema = map[string]interface{}{"properties": properties}
+	}
+
+	switch value := example.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[k] = s.exampleMatchers(properties[k], v, depth+1)
+		}
+		return result
+	case []interface{}:
+		if len(value) == 0 {
+			return value
+		}
+		element := s.exampleMatchers(schema["items"], value[0], depth+1)
+		if minimum, ok := numericValue(schema["minItems"]); ok && minimum > 1 {
+			return map[string]interface{}{shorthandEachLike: element, shorthandMin: int(minimum)}
+		}
+		return map[string]interface{}{shorthandEachLike: element}
+	case string:
+		if pattern := stringPattern(schema); pattern != "" {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(value) {
+				return map[string]interface{}{shorthandRegex: pattern, shorthandExample: value}
+			}
+		}
+	}
+	return map[string]interface{}{shorthandLike: example}
+}
+
+// stringPattern returns the regex that values of a string schema match, if it constrains them.
+func stringPattern(schema map[string]interface{}) string {
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		alternatives := make([]string, 0, len(enum))
+		for _, value := range enum {
+			if text, ok := value.(string); ok {
+				alternatives = append(alternatives, regexp.QuoteMeta(text))
+			}
+		}
+		return "^(" + strings.Join(alternatives, "|") + ")$"
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		return pattern
+	}
+	format, _ := schema["format"].(string)
+	return formatPatterns[format]
+}
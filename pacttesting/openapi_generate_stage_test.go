@@ -0,0 +1,104 @@
+package pacttesting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPIGenerateStage struct {
+	t         *testing.T
+	spec      *OpenAPISpec
+	generated []*GeneratedPact
+	dir       string
+	err       error
+}
+
+func OpenAPIGenerateTest(t *testing.T) (*openAPIGenerateStage, *openAPIGenerateStage, *openAPIGenerateStage) {
+	t.Helper()
+	s := &openAPIGenerateStage{t: t}
+	return s, s, s
+}
+
+func (s *openAPIGenerateStage) and() *openAPIGenerateStage {
+	return s
+}
+
+func (s *openAPIGenerateStage) an_openapi_document(path string) *openAPIGenerateStage {
+	spec, err := LoadOpenAPISpec(path)
+	require.NoError(s.t, err)
+	s.spec = spec
+	return s
+}
+
+func (s *openAPIGenerateStage) pacts_are_generated(selectors ...OperationSelector) *openAPIGenerateStage {
+	s.generated, s.err = s.spec.GeneratePacts(GenerateOptions{Consumer: "go-pact-testing", Selectors: selectors})
+	return s
+}
+
+func (s *openAPIGenerateStage) the_generated_pacts_are_written() *openAPIGenerateStage {
+	require.NoError(s.t, s.err)
+	s.dir = s.t.TempDir()
+	s.err = WriteGeneratedPacts(s.generated, s.dir)
+	return s
+}
+
+func (s *openAPIGenerateStage) no_generate_error_is_returned() *openAPIGenerateStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *openAPIGenerateStage) the_generated_files_are(names ...string) *openAPIGenerateStage {
+	actual := make([]string, len(s.generated))
+	for i, g := range s.generated {
+		actual[i] = g.FileName()
+	}
+	assert.ElementsMatch(s.t, names, actual)
+	return s
+}
+
+func (s *openAPIGenerateStage) the_operation_has_provider_states(operationID string, states ...string) *openAPIGenerateStage {
+	var found []string
+	for _, g := range s.generated {
+		if g.Operation.ID != operationID {
+			continue
+		}
+		for _, interaction := range documentInteractions(g.document, "interactions") {
+			found = append(found, providerStateNames(interaction)...)
+		}
+	}
+	assert.ElementsMatch(s.t, states, found)
+	return s
+}
+
+func (s *openAPIGenerateStage) the_written_pacts_load() *openAPIGenerateStage {
+	loader := &PactLoader{Dir: s.dir}
+	for _, g := range s.generated {
+		_, err := loader.ResolvePact(g.FileName())
+		assert.NoError(s.t, err, g.FileName())
+	}
+	return s
+}
+
+func (s *openAPIGenerateStage) the_written_pacts_have_no_openapi_violations() *openAPIGenerateStage {
+	paths := make([]string, len(s.generated))
+	for i, g := range s.generated {
+		paths[i] = filepath.Join(s.dir, g.FileName())
+	}
+	violations, err := s.spec.ValidateFixtureFiles(paths...)
+	require.NoError(s.t, err)
+	assert.Empty(s.t, violations)
+	return s
+}
+
+func (s *openAPIGenerateStage) the_written_pact_equals_file(name, path string) *openAPIGenerateStage {
+	expected, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	actual, err := os.ReadFile(filepath.Join(s.dir, name))
+	require.NoError(s.t, err)
+	assert.Equal(s.t, string(expected), string(actual))
+	return s
+}
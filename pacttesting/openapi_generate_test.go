@@ -0,0 +1,103 @@
+package pacttesting
+
+import "testing"
+
+func TestOpenAPIGenerate_FixturePerOperationAndResponse(t *testing.T) {
+	given, when, then := OpenAPIGenerateTest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		pacts_are_generated()
+
+	then.
+		no_generate_error_is_returned().and().
+		the_generated_files_are(
+			"testservicea.get.test.200.json",
+			"testservicea.listOrganisations.200.json",
+			"testservicea.createOrganisation.201.json",
+			"testservicea.createOrganisation.400.json",
+			"testservicea.getOrganisation.200.json",
+		)
+}
+
+func TestOpenAPIGenerate_EachResponseHasItsOwnProviderState(t *testing.T) {
+	given, when, then := OpenAPIGenerateTest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		pacts_are_generated(WithOperationID("createOrganisation"))
+
+	then.
+		no_generate_error_is_returned().and().
+		the_operation_has_provider_states("createOrganisation",
+			"createOrganisation returns 201", "createOrganisation returns 400")
+}
+
+func TestOpenAPIGenerate_GeneratedFixturesSatisfyTheDocument(t *testing.T) {
+	given, when, then := OpenAPIGenerateTest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		pacts_are_generated().and().
+		the_generated_pacts_are_written()
+
+	then.
+		no_generate_error_is_returned().and().
+		the_written_pacts_load().and().
+		the_written_pacts_have_no_openapi_violations()
+}
+
+func TestOpenAPIGenerate_BodiesUseTypeAndFormatMatchers(t *testing.T) {
+	given, when, then := OpenAPIGenerateTest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		pacts_are_generated(WithOperationID("getOrganisation")).and().
+		the_generated_pacts_are_written()
+
+	then.
+		no_generate_error_is_returned().and().
+		the_generated_files_are("testservicea.getOrganisation.200.json").and().
+		the_written_pact_equals_file("testservicea.getOrganisation.200.json", "openapipacts/testservicea.getOrganisation.200.json")
+}
+
+func TestOpenAPIGenerate_OperationsAreFiltered(t *testing.T) {
+	given, when, then := OpenAPIGenerateTest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		pacts_are_generated(WithTag("organisations"))
+
+	then.
+		no_generate_error_is_returned().and().
+		the_generated_files_are(
+			"testservicea.listOrganisations.200.json",
+			"testservicea.createOrganisation.201.json",
+			"testservicea.createOrganisation.400.json",
+			"testservicea.getOrganisation.200.json",
+		)
+}
+
+func TestOpenAPIGenerate_OperationsWithoutIDAreNamedByMethodAndPath(t *testing.T) {
+	given, when, then := OpenAPIGenerateTest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		pacts_are_generated(WithPathPrefix("/test"))
+
+	then.
+		no_generate_error_is_returned().and().
+		the_generated_files_are("testservicea.get.test.200.json")
+}
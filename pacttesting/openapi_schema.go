@@ -0,0 +1,285 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//nolint:gochecknoglobals // compiled once
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// schemaReporter collects problems found while checking a value against a schema.
+type schemaReporter func(location jsonPath, format string, args ...interface{})
+
+// checkSchema checks value against an OpenAPI schema object, supporting the subset of JSON Schema that OpenAPI 3.0
+// and 3.1 documents commonly use. Schemas that cannot be interpreted accept any value.
+func (s *OpenAPISpec) checkSchema(schemaNode interface{}, value interface{}, location jsonPath, report schemaReporter) {
+	schema := s.resolveSchema(schemaNode)
+	if schema == nil {
+		return
+	}
+
+	for _, sub := range schemaList(schema["allOf"]) {
+		s.checkSchema(sub, value, location, report)
+	}
+	// oneOf is checked like anyOf: examples often satisfy several loosely written alternatives
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		alternatives := schemaList(schema[keyword])
+		if len(alternatives) > 0 && !s.matchesAny(alternatives, value) {
+			report(location, "%s does not match any of the %s schemas", describeValue(value), keyword)
+		}
+	}
+	if not, ok := schema["not"]; ok && s.matches(not, value) {
+		report(location, "%s matches a schema it must not match", describeValue(value))
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if canonicalJSON(normaliseNumbers(allowed)) == canonicalJSON(normaliseNumbers(value)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report(location, "%s is not one of %s", describeValue(value), canonicalJSON(enum))
+		}
+	}
+
+	types := schemaTypes(schema)
+	kind := schemaKind(value)
+	if value == nil {
+		if len(types) > 0 && !containsString(types, "null") && schema["nullable"] != true {
+			report(location, "null is not allowed")
+		}
+		return
+	}
+	if len(types) > 0 && !containsString(types, kind) && !(kind == "integer" && containsString(types, "number")) {
+		report(location, "expected %s, got %s", strings.Join(types, " or "), kind)
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.checkString(schema, v, location, report)
+	case []interface{}:
+		checkCount(schema, "minItems", "maxItems", len(v), "items", location, report)
+		for i, item := range v {
+			if items, ok := schema["items"]; ok {
+				s.checkSchema(items, item, location.index(i), report)
+			}
+		}
+	case map[string]interface{}:
+		s.checkObject(schema, v, location, report)
+	default:
+		if n, ok := numericValue(v); ok {
+			checkNumber(schema, n, location, report)
+		}
+	}
+}
+
+func (s *OpenAPISpec) checkString(schema map[string]interface{}, v string, location jsonPath, report schemaReporter) {
+	checkCount(schema, "minLength", "maxLength", utf8.RuneCountInString(v), "characters", location, report)
+	if pattern, ok := schema["pattern"].(string); ok {
+		// ECMA regexes Go cannot compile are skipped
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+			report(location, "%q does not match pattern %q", v, pattern)
+		}
+	}
+	format, _ := schema["format"].(string)
+	valid := true
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		valid = err == nil
+	case "uuid":
+		valid = uuidPattern.MatchString(v)
+	case "email":
+		_, err := mail.ParseAddress(v)
+		valid = err == nil
+	case "uri":
+		u, err := url.Parse(v)
+		valid = err == nil && u.Scheme != ""
+	}
+	if !valid {
+		report(location, "%q is not a valid %s", v, format)
+	}
+}
+
+func (s *OpenAPISpec) checkObject(schema map[string]interface{}, v map[string]interface{}, location jsonPath, report schemaReporter) {
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		if key, ok := name.(string); ok {
+			if _, present := v[key]; !present {
+				report(location, "required property %q is missing", key)
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, key := range sortedKeys(v) {
+		if property, ok := properties[key]; ok {
+			s.checkSchema(property, v[key], location.key(key), report)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				report(location.key(key), "property %q is not declared", key)
+			}
+		case map[string]interface{}:
+			s.checkSchema(additional, v[key], location.key(key), report)
+		}
+	}
+	checkCount(schema, "minProperties", "maxProperties", len(v), "properties", location, report)
+}
+
+func checkNumber(schema map[string]interface{}, n float64, location jsonPath, report schemaReporter) {
+	if minimum, ok := numericValue(schema["minimum"]); ok {
+		// OpenAPI 3.0 marks exclusive bounds with a boolean, 3.1 gives the bound itself
+		if schema["exclusiveMinimum"] == true && n <= minimum || n < minimum {
+			report(location, "%v is below the minimum %v", n, minimum)
+		}
+	}
+	if minimum, ok := numericValue(schema["exclusiveMinimum"]); ok && n <= minimum {
+		report(location, "%v is not above %v", n, minimum)
+	}
+	if maximum, ok := numericValue(schema["maximum"]); ok {
+		if schema["exclusiveMaximum"] == true && n >= maximum || n > maximum {
+			report(location, "%v is above the maximum %v", n, maximum)
+		}
+	}
+	if maximum, ok := numericValue(schema["exclusiveMaximum"]); ok && n >= maximum {
+		report(location, "%v is not below %v", n, maximum)
+	}
+	if multiple, ok := numericValue(schema["multipleOf"]); ok && multiple > 0 {
+		if q := n / multiple; math.Abs(q-math.Round(q)) > 1e-9 {
+			report(location, "%v is not a multiple of %v", n, multiple)
+		}
+	}
+}
+
+func checkCount(schema map[string]interface{}, minKey, maxKey string, count int, unit string, location jsonPath, report schemaReporter) {
+	if minimum, ok := numericValue(schema[minKey]); ok && float64(count) < minimum {
+		report(location, "has %d %s, fewer than %s %v", count, unit, minKey, minimum)
+	}
+	if maximum, ok := numericValue(schema[maxKey]); ok && float64(count) > maximum {
+		report(location, "has %d %s, more than %s %v", count, unit, maxKey, maximum)
+	}
+}
+
+func (s *OpenAPISpec) matches(schema interface{}, value interface{}) bool {
+	ok := true
+	s.checkSchema(schema, value, jsonPath{}, func(jsonPath, string, ...interface{}) { ok = false })
+	return ok
+}
+
+func (s *OpenAPISpec) matchesAny(schemas []interface{}, value interface{}) bool {
+	for _, schema := range schemas {
+		if s.matches(schema, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSchema follows $ref until it reaches a schema object, returning nil for anything else.
+func (s *OpenAPISpec) resolveSchema(node interface{}) map[string]interface{} {
+	schema, _ := s.resolve(node).(map[string]interface{})
+	return schema
+}
+
+func schemaList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+// schemaTypes returns the declared types of a schema: "type" is a string in OpenAPI 3.0 and may be a list in 3.1.
+func schemaTypes(schema map[string]interface{}) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// schemaKind returns the JSON Schema type of a decoded JSON value, telling integers apart from other numbers.
+func schemaKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if n, ok := numericValue(v); ok {
+		if n == math.Trunc(n) && !strings.ContainsAny(fmt.Sprint(v), ".eE") {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func describeValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return schemaKind(v)
+}
+
+// normaliseNumbers converts numbers to float64 so that 1 and 1.0 compare equal.
+func normaliseNumbers(v interface{}) interface{} {
+	if n, ok := numericValue(v); ok {
+		return n
+	}
+	return v
+}
+
+// coerceParameter converts a path, query or header parameter string to the type its schema declares, so that
+// it can be checked like a body value.
+func (s *OpenAPISpec) coerceParameter(schemaNode interface{}, raw string) interface{} {
+	schema := s.resolveSchema(schemaNode)
+	types := schemaTypes(schema)
+	switch {
+	case containsString(types, "integer"), containsString(types, "number"):
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	case containsString(types, "boolean"):
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	case containsString(types, "array"):
+		items := strings.Split(raw, ",")
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			values[i] = s.coerceParameter(schema["items"], item)
+		}
+		return values
+	}
+	return raw
+}
@@ -0,0 +1,104 @@
+package pacttesting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPIStage struct {
+	t          *testing.T
+	spec       *OpenAPISpec
+	pact       []byte
+	violations []OpenAPIViolation
+	err        error
+}
+
+func OpenAPITest(t *testing.T) (*openAPIStage, *openAPIStage, *openAPIStage) {
+	t.Helper()
+	s := &openAPIStage{t: t}
+	return s, s, s
+}
+
+func (s *openAPIStage) and() *openAPIStage {
+	return s
+}
+
+func (s *openAPIStage) an_openapi_document(path string) *openAPIStage {
+	spec, err := LoadOpenAPISpec(path)
+	require.NoError(s.t, err)
+	s.spec = spec
+	return s
+}
+
+func (s *openAPIStage) openapi_content(content string) *openAPIStage {
+	s.spec, s.err = ParseOpenAPISpec([]byte(content))
+	return s
+}
+
+func (s *openAPIStage) pact_content(content string) *openAPIStage {
+	s.pact = []byte(content)
+	return s
+}
+
+func (s *openAPIStage) the_pact_files_are_checked(paths ...string) *openAPIStage {
+	s.violations, s.err = s.spec.ValidatePactFiles(paths...)
+	return s
+}
+
+func (s *openAPIStage) the_fixture_files_are_checked(paths ...string) *openAPIStage {
+	s.violations, s.err = s.spec.ValidateFixtureFiles(paths...)
+	return s
+}
+
+func (s *openAPIStage) the_fixtures_are_checked_with_variables(
+	dir string,
+	variables map[string]string,
+	names ...string,
+) *openAPIStage {
+	s.violations, s.err = s.spec.ValidateFixtures(&PactLoader{Dir: dir, Variables: variables}, names...)
+	return s
+}
+
+func (s *openAPIStage) the_pact_is_checked() *openAPIStage {
+	s.violations, s.err = s.spec.ValidatePact(s.pact)
+	return s
+}
+
+func (s *openAPIStage) no_openapi_error_is_returned() *openAPIStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *openAPIStage) an_openapi_error_is_returned_containing(text string) *openAPIStage {
+	require.Error(s.t, s.err)
+	assert.Contains(s.t, s.err.Error(), text)
+	return s
+}
+
+func (s *openAPIStage) there_are_no_violations() *openAPIStage {
+	assert.Empty(s.t, s.violations)
+	return s
+}
+
+// the_violations_are compares "description: location" of every violation, in the order reported.
+func (s *openAPIStage) the_violations_are(expected ...string) *openAPIStage {
+	actual := make([]string, len(s.violations))
+	for i, v := range s.violations {
+		actual[i] = v.Description + ": " + v.Location
+	}
+	assert.Equal(s.t, expected, actual)
+	return s
+}
+
+func (s *openAPIStage) a_violation_message_contains(text string) *openAPIStage {
+	for _, v := range s.violations {
+		if strings.Contains(v.String(), text) {
+			return s
+		}
+	}
+	assert.Failf(s.t, "no matching violation", "no violation contains %q in %v", text, s.violations)
+	return s
+}
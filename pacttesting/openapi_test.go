@@ -0,0 +1,152 @@
+package pacttesting
+
+import "testing"
+
+func TestOpenAPI_ValidPactHasNoViolations(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		the_fixture_files_are_checked("openapipacts/testservicea.valid.json")
+
+	then.
+		no_openapi_error_is_returned().and().
+		there_are_no_violations()
+}
+
+func TestOpenAPI_FixturesWithFragmentsAreChecked(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		the_fixture_files_are_checked("pacts/testservicea.get.test.json", "pacts/testservicea.get.refs.test.json")
+
+	then.
+		no_openapi_error_is_returned().and().
+		there_are_no_violations()
+}
+
+func TestOpenAPI_YAMLFixturesAreChecked(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		the_fixture_files_are_checked("pacts/testservicea.get.yaml.test.yaml")
+
+	then.
+		no_openapi_error_is_returned().and().
+		there_are_no_violations()
+}
+
+func TestOpenAPI_FixtureVariablesAreResolved(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		the_fixtures_are_checked_with_variables("pacts", map[string]string{"ORGANISATION_ID": "42"},
+			"testservicea.get.variables.test")
+
+	then.
+		no_openapi_error_is_returned().and().
+		a_violation_message_contains(`(GET /v1/organisations/42): request.path id: "42" is not a valid uuid`)
+}
+
+func TestOpenAPI_FixturesWithoutVariableValuesAreAnError(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		the_fixture_files_are_checked("pacts/testservicea.get.variables.test.json")
+
+	then.
+		an_openapi_error_is_returned_containing("unresolved variables")
+}
+
+func TestOpenAPI_ViolationsAreReportedPerInteraction(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		an_openapi_document("openapipacts/testservicea.openapi.yaml")
+
+	when.
+		the_pact_files_are_checked("openapipacts/testservicea.invalid.json")
+
+	then.
+		no_openapi_error_is_returned().and().
+		the_violations_are(
+			"Request for an undeclared path: request.path",
+			"Request with an undeclared method: request.method",
+			"Request for an organisation with an invalid id: request.path id",
+			"Request for organisations with invalid parameters: request.query limit",
+			"Request for organisations with invalid parameters: request.header X-Request-Id",
+			"Request for organisations with invalid parameters: request.query sort",
+			"Request expecting an undeclared status: response.status",
+			"Request expecting an invalid organisation: response.body $.colour",
+			"Request expecting an invalid organisation: response.body $.name",
+			"Request expecting an invalid organisation: response.body $.status",
+			"Request creating an organisation without a name: request.body $",
+			"Request creating an organisation without a name: response.body",
+		).and().
+		a_violation_message_contains(`(GET /v1/organisations/42): request.path id: "42" is not a valid uuid`).and().
+		a_violation_message_contains(`response.body $.status: "closed" is not one of ["active","suspended"]`)
+}
+
+func TestOpenAPI_SchemaCombinatorsAndNullableTypes(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		openapi_content(`{"openapi": "3.1.0", "paths": {"/pets": {"get": {"responses": {"200": {"content": {"application/json": {
+			"schema": {"type": "array", "items": {"oneOf": [
+				{"type": "object", "required": ["bark"], "properties": {"bark": {"type": ["string", "null"]}}},
+				{"type": "object", "required": ["meow"], "properties": {"meow": {"type": "boolean"}}}
+			]}}}}}}}}}}`).and().
+		pact_content(`{"interactions": [{"description": "Request for pets", "request": {"method": "GET", "path": "/pets"},
+			"response": {"status": 200, "body": [{"bark": null}, {"meow": true}, {"meow": "yes"}]}}]}`)
+
+	when.
+		the_pact_is_checked()
+
+	then.
+		no_openapi_error_is_returned().and().
+		the_violations_are("Request for pets: response.body $[2]")
+}
+
+func TestOpenAPI_PactsAreCheckedAsTheyAre(t *testing.T) {
+	given, when, then := OpenAPITest(t)
+
+	given.
+		openapi_content(`{"openapi": "3.0.3", "paths": {"/search": {"post": {
+			"requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {
+				"name": {"type": "object", "required": ["$like"], "properties": {"$like": {"type": "string"}}}}}}}},
+			"responses": {"200": {"description": "results"}}}}}}`).and().
+		pact_content(`{"interactions": [{"description": "Search by name",
+			"request": {"method": "POST", "path": "/search", "body": {"name": {"$like": "org%"}}},
+			"response": {"status": 200}}]}`)
+
+	when.
+		the_pact_is_checked()
+
+	then.
+		no_openapi_error_is_returned().and().
+		there_are_no_violations()
+}
+
+func TestOpenAPI_OnlyOpenAPI3IsSupported(t *testing.T) {
+	given, _, then := OpenAPITest(t)
+
+	given.
+		openapi_content(`{"swagger": "2.0", "paths": {}}`)
+
+	then.
+		an_openapi_error_is_returned_containing("only OpenAPI 3 documents are supported")
+}
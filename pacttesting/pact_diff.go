@@ -0,0 +1,479 @@
+package pacttesting
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ChangeKind classifies a PactChange.
+type ChangeKind string
+
+const (
+	ChangeAdded     ChangeKind = "added"
+	ChangeRemoved   ChangeKind = "removed"
+	ChangeModified  ChangeKind = "changed"
+	ChangeLoosened  ChangeKind = "loosened"
+	ChangeTightened ChangeKind = "tightened"
+)
+
+// PactChange is a single difference between two versions of a pact.
+type PactChange struct {
+	Description    string   `json:"description"`
+	ProviderStates []string `json:"providerStates,omitempty"`
+	// Location is the part of the interaction that changed, e.g. "interaction", "request.path",
+	// "response.body $.items[0].id" or "response.matchingRules body $.id".
+	Location string      `json:"location"`
+	Kind     ChangeKind  `json:"kind"`
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+	// Breaking is set when a provider that satisfied the old pact may fail to verify the new one.
+	Breaking bool `json:"breaking"`
+}
+
+func (c PactChange) String() string {
+	impact := "non-breaking"
+	if c.Breaking {
+		impact = "BREAKING"
+	}
+	state := ""
+	if len(c.ProviderStates) > 0 {
+		state = fmt.Sprintf(" given %q", strings.Join(c.ProviderStates, ", "))
+	}
+	detail := ""
+	switch c.Kind {
+	case ChangeModified, ChangeLoosened, ChangeTightened:
+		detail = fmt.Sprintf(": %s -> %s", canonicalJSON(c.Old), canonicalJSON(c.New))
+	case ChangeAdded:
+		if c.New != nil {
+			detail = ": " + canonicalJSON(c.New)
+		}
+	case ChangeRemoved:
+		if c.Old != nil {
+			detail = ": " + canonicalJSON(c.Old)
+		}
+	}
+	return fmt.Sprintf("[%s] %q%s: %s %s%s", impact, c.Description, state, c.Location, c.Kind, detail)
+}
+
+// PactDiff lists the changes between two versions of the pact between a consumer and a provider.
+type PactDiff struct {
+	Consumer string       `json:"consumer"`
+	Provider string       `json:"provider"`
+	Changes  []PactChange `json:"changes"`
+}
+
+// Breaking reports whether any change may break verification of the provider.
+func (d *PactDiff) Breaking() bool {
+	for _, c := range d.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffPactFiles reads two versions of a pact and returns DiffPacts of their contents.
+func DiffPactFiles(oldPath, newPath string) (*PactDiff, error) {
+	oldPact, err := os.ReadFile(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading pact file '%s': %w", oldPath, err)
+	}
+	newPact, err := os.ReadFile(newPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading pact file '%s': %w", newPath, err)
+	}
+	return DiffPacts(oldPact, newPact)
+}
+
+// DiffPacts compares two versions of a pact. Interactions (or messages) are matched by description and
+// provider state; unmatched ones are reported as added or removed. For matched interactions it reports
+// changes to the request method, path, query, headers and body, the response status, headers and body,
+// message contents and metadata, and matching rules that were loosened or tightened.
+//
+// Each change is classified from the provider's point of view: anything the provider now has to accept or
+// produce that it did not before is breaking, while removed expectations and loosened response matchers are not.
+func DiffPacts(oldPact, newPact []byte) (*PactDiff, error) {
+	oldDoc, err := parsePactDocument(oldPact)
+	if err != nil {
+		return nil, fmt.Errorf("parsing old pact: %w", err)
+	}
+	newDoc, err := parsePactDocument(newPact)
+	if err != nil {
+		return nil, fmt.Errorf("parsing new pact: %w", err)
+	}
+
+	diff := &PactDiff{
+		Consumer: participantName(newDoc, "consumer"),
+		Provider: participantName(newDoc, "provider"),
+		Changes:  []PactChange{},
+	}
+	for _, field := range pactInteractionFields {
+		oldInteractions := documentInteractions(oldDoc, field)
+		newInteractions := documentInteractions(newDoc, field)
+		newByKey := make(map[string]map[string]interface{}, len(newInteractions))
+		for _, i := range newInteractions {
+			newByKey[interactionKey(i)] = i
+		}
+		oldByKey := make(map[string]map[string]interface{}, len(oldInteractions))
+		for _, i := range oldInteractions {
+			key := interactionKey(i)
+			oldByKey[key] = i
+			if _, ok := newByKey[key]; !ok {
+				d := newInteractionDiff(i)
+				d.add("interaction", ChangeRemoved, nil, nil, false)
+				diff.Changes = append(diff.Changes, d.changes...)
+			}
+		}
+		for _, i := range newInteractions {
+			d := newInteractionDiff(i)
+			old, ok := oldByKey[interactionKey(i)]
+			switch {
+			case !ok:
+				d.add("interaction", ChangeAdded, nil, nil, true)
+			case field == "messages":
+				d.diffMessage(old, i)
+			default:
+				d.diffHTTPInteraction(old, i)
+			}
+			diff.Changes = append(diff.Changes, d.changes...)
+		}
+	}
+	return diff, nil
+}
+
+type interactionDiff struct {
+	description string
+	states      []string
+	changes     []PactChange
+}
+
+func newInteractionDiff(interaction map[string]interface{}) *interactionDiff {
+	description, _ := interaction["description"].(string)
+	return &interactionDiff{description: description, states: providerStateNames(interaction)}
+}
+
+func (d *interactionDiff) add(location string, kind ChangeKind, before, after interface{}, breaking bool) {
+	d.changes = append(d.changes, PactChange{
+		Description:    d.description,
+		ProviderStates: d.states,
+		Location:       location,
+		Kind:           kind,
+		Old:            before,
+		New:            after,
+		Breaking:       breaking,
+	})
+}
+
+func (d *interactionDiff) diffHTTPInteraction(oldInteraction, newInteraction map[string]interface{}) {
+	oldRequest, _ := oldInteraction["request"].(map[string]interface{})
+	newRequest, _ := newInteraction["request"].(map[string]interface{})
+	oldMethod, _ := oldRequest["method"].(string)
+	newMethod, _ := newRequest["method"].(string)
+	if !strings.EqualFold(oldMethod, newMethod) {
+		d.add("request.method", ChangeModified, oldMethod, newMethod, true)
+	}
+	if oldRequest["path"] != newRequest["path"] {
+		d.add("request.path", ChangeModified, oldRequest["path"], newRequest["path"], true)
+	}
+	d.diffEntries("request.query", queryValues(oldRequest["query"]), queryValues(newRequest["query"]),
+		func(ChangeKind, string) bool { return true })
+	// providers ignore headers they do not know, so only removed or changed request headers can break them
+	d.diffEntries("request.headers", headerValues(oldRequest["headers"]), headerValues(newRequest["headers"]),
+		func(kind ChangeKind, _ string) bool { return kind != ChangeAdded })
+	d.diffBody("request.body", oldRequest["body"], newRequest["body"], nil)
+	d.diffMatchingRules("request.matchingRules", oldRequest, newRequest, false)
+
+	oldResponse, _ := oldInteraction["response"].(map[string]interface{})
+	newResponse, _ := newInteraction["response"].(map[string]interface{})
+	if canonicalJSON(oldResponse["status"]) != canonicalJSON(newResponse["status"]) {
+		d.add("response.status", ChangeModified, oldResponse["status"], newResponse["status"], true)
+	}
+	newRules := normaliseMatchingRules(newResponse)
+	d.diffEntries("response.headers", headerValues(oldResponse["headers"]), headerValues(newResponse["headers"]),
+		expectationBreaking(newRules, "header"))
+	d.diffBody("response.body", oldResponse["body"], newResponse["body"], newRules)
+	d.diffMatchingRules("response.matchingRules", oldResponse, newResponse, true)
+}
+
+func (d *interactionDiff) diffMessage(oldMessage, newMessage map[string]interface{}) {
+	newRules := normaliseMatchingRules(newMessage)
+	d.diffEntries("metaData", metaDataValues(oldMessage["metaData"]), metaDataValues(newMessage["metaData"]),
+		expectationBreaking(newRules, "metadata"))
+	d.diffBody("contents", oldMessage["contents"], newMessage["contents"], newRules)
+	d.diffMatchingRules("matchingRules", oldMessage, newMessage, true)
+}
+
+// expectationBreaking classifies changes to expected headers or metadata: new expectations are breaking,
+// dropped ones are not, and changed values only when no matcher covers the entry.
+func expectationBreaking(rules map[string]matchingRule, category string) func(ChangeKind, string) bool {
+	return func(kind ChangeKind, name string) bool {
+		switch kind {
+		case ChangeRemoved:
+			return false
+		case ChangeModified:
+			rule, ok := rules[matchingRule{category: category, path: ruleEntryName(category, name)}.key()]
+			return !ok || ruleStrictness(rule) >= matcherStrictness("equality")
+		}
+		return true
+	}
+}
+
+// diffEntries compares two flat name/value maps such as headers or query parameters.
+func (d *interactionDiff) diffEntries(location string, before, after map[string]interface{},
+	breaking func(kind ChangeKind, name string) bool,
+) {
+	for _, name := range sortedKeys(before) {
+		if _, ok := after[name]; !ok {
+			d.add(location+" "+name, ChangeRemoved, before[name], nil, breaking(ChangeRemoved, name))
+		}
+	}
+	for _, name := range sortedKeys(after) {
+		value, ok := before[name]
+		switch {
+		case !ok:
+			d.add(location+" "+name, ChangeAdded, nil, after[name], breaking(ChangeAdded, name))
+		case canonicalJSON(value) != canonicalJSON(after[name]):
+			d.add(location+" "+name, ChangeModified, value, after[name], breaking(ChangeModified, name))
+		}
+	}
+}
+
+// diffBody compares example bodies. rules are the new matching rules of a response or message body;
+// they are nil for request bodies, where every change is breaking.
+func (d *interactionDiff) diffBody(location string, before, after interface{}, rules map[string]matchingRule) {
+	d.diffBodyNode(location, jsonPath{}, before, after, rules)
+}
+
+func (d *interactionDiff) diffBodyNode(location string, path jsonPath, before, after interface{},
+	rules map[string]matchingRule,
+) {
+	at := location + " " + path.String()
+	if rules == nil {
+		// request body: the provider will receive something different
+		if canonicalJSON(before) != canonicalJSON(after) {
+			switch {
+			case before == nil:
+				d.add(at, ChangeAdded, nil, after, true)
+			case after == nil:
+				d.add(at, ChangeRemoved, before, nil, true)
+			default:
+				d.add(at, ChangeModified, before, after, true)
+			}
+		}
+		return
+	}
+
+	switch beforeValue := before.(type) {
+	case map[string]interface{}:
+		if afterValue, ok := after.(map[string]interface{}); ok {
+			for _, k := range sortedKeys(beforeValue) {
+				if _, ok := afterValue[k]; !ok {
+					d.add(location+" "+path.key(k).String(), ChangeRemoved, beforeValue[k], nil, false)
+				}
+			}
+			for _, k := range sortedKeys(afterValue) {
+				child, ok := beforeValue[k]
+				if !ok {
+					d.add(location+" "+path.key(k).String(), ChangeAdded, nil, afterValue[k], true)
+					continue
+				}
+				d.diffBodyNode(location, path.key(k), child, afterValue[k], rules)
+			}
+			return
+		}
+	case []interface{}:
+		if afterValue, ok := after.([]interface{}); ok {
+			// element counts only matter when no matcher (e.g. eachLike/min) covers the array
+			covered := bodyRuleCovering(rules, path) != nil
+			for i := range beforeValue {
+				if i >= len(afterValue) {
+					d.add(location+" "+path.index(i).String(), ChangeRemoved, beforeValue[i], nil, false)
+					continue
+				}
+				d.diffBodyNode(location, path.index(i), beforeValue[i], afterValue[i], rules)
+			}
+			for i := len(beforeValue); i < len(afterValue); i++ {
+				d.add(location+" "+path.index(i).String(), ChangeAdded, nil, afterValue[i], !covered)
+			}
+			return
+		}
+	}
+
+	if canonicalJSON(before) == canonicalJSON(after) {
+		return
+	}
+	switch {
+	case before == nil:
+		d.add(at, ChangeAdded, nil, after, true)
+	case after == nil:
+		d.add(at, ChangeRemoved, before, nil, false)
+	default:
+		rule := bodyRuleCovering(rules, path)
+		breaking := rule == nil || ruleStrictness(*rule) >= matcherStrictness("equality") || jsonKind(before) != jsonKind(after)
+		d.add(at, ChangeModified, before, after, breaking)
+	}
+}
+
+// bodyRuleCovering returns the most specific body matching rule that applies to the node at path.
+func bodyRuleCovering(rules map[string]matchingRule, path jsonPath) *matchingRule {
+	var best *matchingRule
+	for _, key := range sortedRuleKeys(rules) {
+		rule := rules[key]
+		if rule.category != "body" || !ruleCovers(rule.path, path.String()) {
+			continue
+		}
+		if best == nil || len(rule.path) > len(best.path) {
+			best = &rule
+		}
+	}
+	return best
+}
+
+func jsonKind(v interface{}) string {
+	return fmt.Sprintf("%T", v)
+}
+
+// diffMatchingRules reports matching rules that were added, removed or changed. Only rules on what
+// the provider produces (responses and messages) can break the provider; request rules only affect
+// how the consumer's requests are matched by the mock service.
+func (d *interactionDiff) diffMatchingRules(location string, oldOwner, newOwner map[string]interface{}, providerSide bool) {
+	oldRules := normaliseMatchingRules(oldOwner)
+	newRules := normaliseMatchingRules(newOwner)
+	for _, key := range sortedRuleKeys(oldRules) {
+		if _, ok := newRules[key]; !ok {
+			d.add(location+" "+strings.TrimSpace(key), ChangeTightened, ruleMatchers(oldRules[key]), "exact match", providerSide)
+		}
+	}
+	for _, key := range sortedRuleKeys(newRules) {
+		newRule := newRules[key]
+		oldRule, ok := oldRules[key]
+		if !ok {
+			d.add(location+" "+strings.TrimSpace(key), ChangeLoosened, "exact match", ruleMatchers(newRule), false)
+			continue
+		}
+		oldMatchers, newMatchers := ruleMatchers(oldRule), ruleMatchers(newRule)
+		if canonicalJSON(oldMatchers) == canonicalJSON(newMatchers) && oldRule.combine == newRule.combine {
+			continue
+		}
+		switch compareRules(oldRule, newRule) {
+		case ChangeLoosened:
+			d.add(location+" "+strings.TrimSpace(key), ChangeLoosened, oldMatchers, newMatchers, false)
+		case ChangeTightened:
+			d.add(location+" "+strings.TrimSpace(key), ChangeTightened, oldMatchers, newMatchers, providerSide)
+		default:
+			d.add(location+" "+strings.TrimSpace(key), ChangeModified, oldMatchers, newMatchers, providerSide)
+		}
+	}
+}
+
+func ruleMatchers(rule matchingRule) []map[string]interface{} {
+	return rule.matchers
+}
+
+// compareRules decides whether a changed rule accepts more (loosened) or fewer (tightened) values.
+// Changes that cannot be ordered, such as a different regex, are reported as ChangeModified.
+func compareRules(oldRule, newRule matchingRule) ChangeKind {
+	oldStrictness, newStrictness := ruleStrictness(oldRule), ruleStrictness(newRule)
+	switch {
+	case newStrictness < oldStrictness:
+		return ChangeLoosened
+	case newStrictness > oldStrictness:
+		return ChangeTightened
+	case len(oldRule.matchers) != 1 || len(newRule.matchers) != 1:
+		return ChangeModified
+	}
+	oldMatcher, newMatcher := oldRule.matchers[0], newRule.matchers[0]
+	if matcherType(oldMatcher) != matcherType(newMatcher) {
+		return ChangeModified
+	}
+	for k := range newMatcher {
+		if k != "min" && k != "max" && canonicalJSON(oldMatcher[k]) != canonicalJSON(newMatcher[k]) {
+			return ChangeModified
+		}
+	}
+	looser, tighter := false, false
+	compareBound := func(key string, looserWhenSmaller bool) {
+		oldBound, oldOK := numericValue(oldMatcher[key])
+		newBound, newOK := numericValue(newMatcher[key])
+		switch {
+		case oldOK && !newOK:
+			looser = true
+		case !oldOK && newOK:
+			tighter = true
+		case oldOK && newOK && newBound != oldBound:
+			if (newBound < oldBound) == looserWhenSmaller {
+				looser = true
+			} else {
+				tighter = true
+			}
+		}
+	}
+	compareBound("min", true)
+	compareBound("max", false)
+	switch {
+	case looser && !tighter:
+		return ChangeLoosened
+	case tighter && !looser:
+		return ChangeTightened
+	}
+	return ChangeModified
+}
+
+func numericValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case interface{ Float64() (float64, error) }:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// queryValues normalises a v2 query string or a v3 query object to a map of parameter to value list.
+func queryValues(query interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	switch q := query.(type) {
+	case string:
+		parsed, err := url.ParseQuery(q)
+		if err != nil {
+			values[q] = []interface{}{}
+			return values
+		}
+		for k, v := range parsed {
+			list := make([]interface{}, len(v))
+			for i, s := range v {
+				list[i] = s
+			}
+			values[k] = list
+		}
+	case map[string]interface{}:
+		for k, v := range q {
+			if s, ok := v.(string); ok {
+				v = []interface{}{s}
+			}
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// headerValues returns headers keyed by lower-cased name, since header names are case-insensitive.
+func headerValues(headers interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	h, _ := headers.(map[string]interface{})
+	for k, v := range h {
+		values[strings.ToLower(k)] = v
+	}
+	return values
+}
+
+func metaDataValues(metaData interface{}) map[string]interface{} {
+	values, _ := metaData.(map[string]interface{})
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values
+}
@@ -0,0 +1,77 @@
+package pacttesting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pactDiffStage struct {
+	t       *testing.T
+	oldPact string
+	newPact string
+	diff    *PactDiff
+}
+
+func PactDiffTest(t *testing.T) (*pactDiffStage, *pactDiffStage, *pactDiffStage) {
+	t.Helper()
+	s := &pactDiffStage{t: t}
+	return s, s, s
+}
+
+func (s *pactDiffStage) and() *pactDiffStage {
+	return s
+}
+
+func (s *pactDiffStage) an_old_pact(path string) *pactDiffStage {
+	s.oldPact = path
+	return s
+}
+
+func (s *pactDiffStage) a_new_pact(path string) *pactDiffStage {
+	s.newPact = path
+	return s
+}
+
+func (s *pactDiffStage) the_pacts_are_compared() *pactDiffStage {
+	diff, err := DiffPactFiles(s.oldPact, s.newPact)
+	require.NoError(s.t, err)
+	s.diff = diff
+	return s
+}
+
+func (s *pactDiffStage) no_changes_are_reported() *pactDiffStage {
+	assert.Empty(s.t, s.diff.Changes)
+	assert.False(s.t, s.diff.Breaking())
+	return s
+}
+
+func (s *pactDiffStage) the_diff_is_breaking() *pactDiffStage {
+	assert.True(s.t, s.diff.Breaking())
+	return s
+}
+
+func (s *pactDiffStage) find(description, location string) *PactChange {
+	for i, c := range s.diff.Changes {
+		if c.Description == description && c.Location == location {
+			return &s.diff.Changes[i]
+		}
+	}
+	return nil
+}
+
+func (s *pactDiffStage) a_change_is_reported(description, location string, kind ChangeKind, breaking bool) *pactDiffStage {
+	change := s.find(description, location)
+	if !assert.NotNil(s.t, change, "no change for %q at %s in %v", description, location, s.diff.Changes) {
+		return s
+	}
+	assert.Equal(s.t, kind, change.Kind, change.String())
+	assert.Equal(s.t, breaking, change.Breaking, change.String())
+	return s
+}
+
+func (s *pactDiffStage) no_change_is_reported(description, location string) *pactDiffStage {
+	assert.Nil(s.t, s.find(description, location))
+	return s
+}
@@ -0,0 +1,78 @@
+package pacttesting
+
+import "testing"
+
+func TestDiff_IdenticalPactsHaveNoChanges(t *testing.T) {
+	given, when, then := PactDiffTest(t)
+
+	given.
+		an_old_pact("diffpacts/testservicea.v1.json").and().
+		a_new_pact("diffpacts/testservicea.v1.json")
+
+	when.
+		the_pacts_are_compared()
+
+	then.
+		no_changes_are_reported()
+}
+
+func TestDiff_ChangesAreClassified(t *testing.T) {
+	given, when, then := PactDiffTest(t)
+
+	given.
+		an_old_pact("diffpacts/testservicea.v1.json").and().
+		a_new_pact("diffpacts/testservicea.v2.json")
+
+	when.
+		the_pacts_are_compared()
+
+	then.
+		the_diff_is_breaking().and().
+		a_change_is_reported("Request for a removed endpoint", "interaction", ChangeRemoved, false).and().
+		a_change_is_reported("Request for a new endpoint", "interaction", ChangeAdded, true).and().
+		a_change_is_reported("Request for a test endpoint B", "request.path", ChangeModified, true).and().
+		a_change_is_reported("Request for a test endpoint A", "request.headers x-request-id", ChangeAdded, false).and().
+		a_change_is_reported("Request for a test endpoint A", "response.body $.id", ChangeModified, false).and().
+		a_change_is_reported("Request for a test endpoint A", "response.body $.legacy", ChangeRemoved, false).and().
+		a_change_is_reported("Request for a test endpoint A", "response.body $.created", ChangeAdded, true).and().
+		a_change_is_reported("Request for a test endpoint A", "response.body $.items[1]", ChangeAdded, false).and().
+		a_change_is_reported("Request for a test endpoint A", "response.matchingRules body $.name", ChangeTightened, true).and().
+		a_change_is_reported("Request for a test endpoint A", "response.matchingRules body $.items", ChangeLoosened, false).and().
+		no_change_is_reported("Request for a test endpoint A", "response.headers content-type")
+}
+
+func TestDiff_CombinedRulesAreRatedByHowTheyCombine(t *testing.T) {
+	given, when, then := PactDiffTest(t)
+
+	given.
+		an_old_pact("diffpacts/testservicea.combined.v1.json").and().
+		a_new_pact("diffpacts/testservicea.combined.v2.json")
+
+	when.
+		the_pacts_are_compared()
+
+	then.
+		a_change_is_reported("Request for a test endpoint with combined rules",
+			"response.matchingRules body $.andTightened", ChangeTightened, true).and().
+		a_change_is_reported("Request for a test endpoint with combined rules",
+			"response.matchingRules body $.andLoosened", ChangeLoosened, false).and().
+		a_change_is_reported("Request for a test endpoint with combined rules",
+			"response.matchingRules body $.orTightened", ChangeTightened, true).and().
+		a_change_is_reported("Request for a test endpoint with combined rules",
+			"response.matchingRules body $.orLoosened", ChangeLoosened, false)
+}
+
+func TestDiff_MessagePacts(t *testing.T) {
+	given, when, then := PactDiffTest(t)
+
+	given.
+		an_old_pact("messagepacts/message2.json").and().
+		a_new_pact("splitpacts/messages.bulk.json")
+
+	when.
+		the_pacts_are_compared()
+
+	then.
+		a_change_is_reported("message 2", "interaction", ChangeRemoved, false).and().
+		a_change_is_reported("message 1", "interaction", ChangeAdded, true)
+}
@@ -0,0 +1,175 @@
+package pacttesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// pactDocument is a pact file decoded into generic values. Unlike PactFile it keeps every field,
+// so tools that rewrite pact files do not drop anything they do not know about.
+type pactDocument = map[string]interface{}
+
+// pactInteractionFields lists the top level arrays holding interactions: HTTP pacts use
+// interactions, message pacts use messages.
+//
+//nolint:gochecknoglobals // constant list
+var pactInteractionFields = []string{"interactions", "messages"}
+
+func readPactDocument(path string) (pactDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading pact file '%s': %w", path, err)
+	}
+	doc, err := parsePactDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing pact file '%s': %w", path, err)
+	}
+	return doc, nil
+}
+
+func parsePactDocument(data []byte) (pactDocument, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("pact must be a JSON object")
+	}
+	return doc, nil
+}
+
+// marshalPactDocument encodes v as indented JSON without escaping HTML characters,
+// which are common in regex matchers.
+func marshalPactDocument(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("encoding pact: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// participantName returns the name of the pact's "consumer" or "provider".
+func participantName(doc pactDocument, role string) string {
+	participant, _ := doc[role].(map[string]interface{})
+	name, _ := participant["name"].(string)
+	return name
+}
+
+// documentError reports a problem at a position in a pact document, so Lint can point at the offending node.
+type documentError struct {
+	location jsonPath
+	err      error
+}
+
+func (e *documentError) Error() string {
+	return e.location.String() + ": " + e.err.Error()
+}
+
+func (e *documentError) Unwrap() error {
+	return e.err
+}
+
+func documentErrorf(location jsonPath, format string, args ...interface{}) error {
+	return &documentError{location: location, err: fmt.Errorf(format, args...)}
+}
+
+// documentSpecMajor returns the major pact specification version declared in the metadata, or 0 if
+// there is none. Fields are checked in the same order Lint uses.
+func documentSpecMajor(doc pactDocument) int {
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	for _, key := range []string{"pactSpecification", "pact-specification", "pactSpecificationVersion"} {
+		value := metadata[key]
+		if spec, isObject := value.(map[string]interface{}); isObject {
+			value = spec["version"]
+		}
+		version, _ := value.(string)
+		if m := specVersionPattern.FindStringSubmatch(version); m != nil {
+			major, _ := strconv.Atoi(m[1])
+			return major
+		}
+	}
+	return 0
+}
+
+// documentInteractions returns the interactions held under field, skipping anything that is not an object.
+func documentInteractions(doc pactDocument, field string) []map[string]interface{} {
+	items, _ := doc[field].([]interface{})
+	interactions := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if interaction, ok := item.(map[string]interface{}); ok {
+			interactions = append(interactions, interaction)
+		}
+	}
+	return interactions
+}
+
+// providerStateKey identifies the provider state(s) of an interaction, covering both the v2
+// providerState string and the v3 providerStates array (including state params).
+func providerStateKey(interaction map[string]interface{}) string {
+	if state, ok := interaction["providerState"].(string); ok {
+		return state
+	}
+	states, _ := interaction["providerStates"].([]interface{})
+	keys := make([]string, 0, len(states))
+	for _, s := range states {
+		state, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if len(state) == 1 {
+			name, _ := state["name"].(string)
+			keys = append(keys, name)
+			continue
+		}
+		keys = append(keys, canonicalJSON(state))
+	}
+	return strings.Join(keys, "\n")
+}
+
+// providerStateNames returns the names of all provider states of an interaction, for display.
+func providerStateNames(interaction map[string]interface{}) []string {
+	if state, ok := interaction["providerState"].(string); ok {
+		return []string{state}
+	}
+	states, _ := interaction["providerStates"].([]interface{})
+	names := make([]string, 0, len(states))
+	for _, s := range states {
+		state, _ := s.(map[string]interface{})
+		if name, ok := state["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// interactionKey identifies an interaction the way the pact mock service and verifier do: by
+// description and provider state.
+func interactionKey(interaction map[string]interface{}) string {
+	description, _ := interaction["description"].(string)
+	return description + "\x00" + providerStateKey(interaction)
+}
+
+// canonicalJSON encodes v with sorted object keys so that equal values produce equal strings.
+func canonicalJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(b)
+}
+
+// pactFileName returns the file name the pact mock service uses for a consumer/provider pair.
+func pactFileName(consumer, provider string) string {
+	normalise := func(name string) string {
+		return strings.ReplaceAll(strings.ToLower(name), " ", "_")
+	}
+	return sanitize(normalise(consumer)+"-"+normalise(provider)) + ".json"
+}
@@ -15,10 +15,19 @@ type PactFile struct {
 	} `json:"consumer"`
 	Interactions []struct {
 		Description    string      `json:"description"`
-		ProviderStates interface{} `json:"providerStates"`
+		ProviderState  string      `json:"providerState,omitempty"`
+		ProviderStates interface{} `json:"providerStates,omitempty"`
 		Request        interface{} `json:"request"`
 		Response       interface{} `json:"response"`
-	} `json:"interactions"`
+	} `json:"interactions,omitempty"`
+	Messages []struct {
+		Description    string      `json:"description"`
+		ProviderState  string      `json:"providerState,omitempty"`
+		ProviderStates interface{} `json:"providerStates,omitempty"`
+		Contents       interface{} `json:"contents"`
+		MetaData       interface{} `json:"metaData,omitempty"`
+		MatchingRules  interface{} `json:"matchingRules,omitempty"`
+	} `json:"messages,omitempty"`
 	Metadata interface{} `json:"metadata"`
 }
 
@@ -32,26 +41,35 @@ func NewPactFile(data []byte) (*PactFile, error) {
 	return pactFile, nil
 }
 
-// Split divides bulk file with many interactions to single-interaction PACT files.
+// Split divides bulk file with many interactions (or messages) to single-interaction PACT files.
 // It's required as a workaround to make bigger PACT test runs working.
 func (f *PactFile) Split() *[]*PactFile {
-	interactionsCount := len(f.Interactions)
-	if interactionsCount == 0 {
+	count := len(f.Interactions) + len(f.Messages)
+	if count == 0 {
 		return nil
 	}
-	files := make([]*PactFile, len(f.Interactions))
-	if interactionsCount == 1 {
-		files[0] = f
-	} else {
-		for idx, interaction := range f.Interactions {
-			singleFile := PactFile{
-				Provider: f.Provider,
-				Consumer: f.Consumer,
-				Metadata: f.Metadata,
-			}
-			singleFile.Interactions = append(singleFile.Interactions, interaction)
-			files[idx] = &singleFile
+	files := make([]*PactFile, 0, count)
+	if count == 1 {
+		files = append(files, f)
+		return &files
+	}
+	for _, interaction := range f.Interactions {
+		singleFile := PactFile{
+			Provider: f.Provider,
+			Consumer: f.Consumer,
+			Metadata: f.Metadata,
+		}
+		singleFile.Interactions = append(singleFile.Interactions, interaction)
+		files = append(files, &singleFile)
+	}
+	for _, message := range f.Messages {
+		singleFile := PactFile{
+			Provider: f.Provider,
+			Consumer: f.Consumer,
+			Metadata: f.Metadata,
 		}
+		singleFile.Messages = append(singleFile.Messages, message)
+		files = append(files, &singleFile)
 	}
 	return &files
 }
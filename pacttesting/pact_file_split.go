@@ -1,66 +1,287 @@
 package pacttesting
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 type PactRequestMatchingFilter = func(map[string]interface{})
 
+// SplitGrouping decides which interactions of a bulk pact file end up in the same output file.
+type SplitGrouping int
+
+const (
+	// SplitPerInteraction writes every interaction to its own file, named after its description.
+	SplitPerInteraction SplitGrouping = iota
+	// SplitPerProviderState writes one file per distinct provider state, named after the state.
+	SplitPerProviderState
+	// SplitPerRequestPath writes one file per distinct request path, named after the path.
+	SplitPerRequestPath
+	// SplitInChunks writes SplitOptions.ChunkSize interactions per file, named after the bulk file.
+	SplitInChunks
+)
+
+// SplitOptions configures SplitPactFile.
+type SplitOptions struct {
+	GroupBy SplitGrouping
+	// ChunkSize is the number of interactions per file when GroupBy is SplitInChunks.
+	ChunkSize int
+	// IndexPath, if set, is where the SplitIndex manifest is written. Relative paths are resolved
+	// against the output directory. Note that a *.json manifest inside the output directory will
+	// be picked up by globs such as "<dir>/*.json".
+	IndexPath string
+	// RequestFilters are applied to the request of every interaction, or to the whole message for message pacts.
+	RequestFilters []PactRequestMatchingFilter
+	// Format writes every output file in the canonical form of FormatPact.
+	Format bool
+}
+
+// SplitIndex maps every file written by SplitPactFile to the interactions it contains.
+type SplitIndex struct {
+	Source string           `json:"source"`
+	Files  []SplitIndexFile `json:"files"`
+}
+
+type SplitIndexFile struct {
+	File         string                  `json:"file"`
+	Interactions []SplitIndexInteraction `json:"interactions"`
+}
+
+type SplitIndexInteraction struct {
+	// Index is the position of the interaction in the source file.
+	Index          int      `json:"index"`
+	Description    string   `json:"description"`
+	ProviderStates []string `json:"providerStates,omitempty"`
+	Method         string   `json:"method,omitempty"`
+	Path           string   `json:"path,omitempty"`
+}
+
 // SplitPactBulkFile reads bulk PACT files, splits it into smaller ones
 // and writes output to destination directory
 func SplitPactBulkFile(bulkFilePath string, outputDirPath string, requestFilters ...PactRequestMatchingFilter) error {
+	_, err := SplitPactFile(bulkFilePath, outputDirPath, SplitOptions{RequestFilters: requestFilters})
+	return err
+}
+
+// SplitPactFile reads a bulk PACT file of HTTP interactions or messages, splits it according to options
+// and writes the output to the destination directory. Every output file keeps the consumer, provider,
+// metadata and any other top level fields of the bulk file. Output file names are filesystem safe and
+// unique within a single split.
+func SplitPactFile(bulkFilePath string, outputDirPath string, options SplitOptions) (*SplitIndex, error) {
+	if options.GroupBy == SplitInChunks && options.ChunkSize < 1 {
+		return nil, fmt.Errorf("chunk size must be positive, got %d", options.ChunkSize)
+	}
 	// prepare output directory
 	if _, outputDirErr := os.Stat(outputDirPath); os.IsNotExist(outputDirErr) {
 		if newDirErr := os.MkdirAll(outputDirPath, os.ModePerm); newDirErr != nil {
-			return fmt.Errorf("couldn't create output directory '%s': %w", outputDirPath, newDirErr)
+			return nil, fmt.Errorf("couldn't create output directory '%s': %w", outputDirPath, newDirErr)
 		}
 	}
 	// read bulk file
 	file, fileErr := os.ReadFile(bulkFilePath)
 	if fileErr != nil {
-		return fmt.Errorf("couldn't read PACT tests from file '%s': %w", bulkFilePath, fileErr)
+		return nil, fmt.Errorf("couldn't read PACT tests from file '%s': %w", bulkFilePath, fileErr)
 	}
-	pactFile, pactFileErr := NewPactFile(file)
-	if pactFileErr != nil {
-		return fmt.Errorf("couldn't parse PACT file '%s': %w", bulkFilePath, pactFileErr)
+	doc, docErr := parsePactDocument(file)
+	if docErr != nil {
+		return nil, fmt.Errorf("couldn't parse PACT file '%s': %w", bulkFilePath, docErr)
 	}
 	// split into smaller files
-	testCases := pactFile.Split()
-	if testCases == nil {
-		return errors.New("No test cases have been found in file: " + bulkFilePath)
-	}
-	for idx, tc := range *testCases {
-		for _, i := range tc.Interactions {
-			requestMatchingRules, reqTypeOk := i.Request.(map[string]interface{})
-			if reqTypeOk {
-				for _, reqFilter := range requestFilters {
-					reqFilter(requestMatchingRules)
-				}
+	field := "interactions"
+	interactions := documentInteractions(doc, field)
+	if len(interactions) == 0 {
+		field = "messages"
+		interactions = documentInteractions(doc, field)
+	}
+	if len(interactions) == 0 {
+		return nil, errors.New("No test cases have been found in file: " + bulkFilePath)
+	}
+	if field == "messages" && options.GroupBy == SplitPerRequestPath {
+		return nil, fmt.Errorf("message pact '%s' has no request paths to group by", bulkFilePath)
+	}
+	for _, i := range interactions {
+		// messages have no request, so filters get the whole message (providerStates, metaData, matchingRules...)
+		target := i
+		if field == "interactions" {
+			request, ok := i["request"].(map[string]interface{})
+			if !ok {
+				continue
 			}
+			target = request
+		}
+		for _, reqFilter := range options.RequestFilters {
+			reqFilter(target)
 		}
+	}
+
+	index := &SplitIndex{Source: bulkFilePath}
+	names := newUniqueFileNames()
+	indexPath := options.IndexPath
+	if indexPath != "" && !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(outputDirPath, indexPath)
+	}
+	if indexPath != "" && filepath.Dir(indexPath) == filepath.Clean(outputDirPath) {
+		names.reserve(filepath.Base(indexPath))
+	}
 
-		json, jsonErr := json.Marshal(tc)
+	for idx, group := range groupInteractions(interactions, bulkFilePath, options) {
+		tc := make(pactDocument, len(doc))
+		for k, v := range doc {
+			tc[k] = v
+		}
+		items := make([]interface{}, len(group.members))
+		entry := SplitIndexFile{File: names.next(group.name)}
+		for n, member := range group.members {
+			items[n] = interactions[member]
+			entry.Interactions = append(entry.Interactions, splitIndexInteraction(member, interactions[member]))
+		}
+		tc[field] = items
+
+		json, jsonErr := marshalPactDocument(tc)
+		if jsonErr == nil && options.Format {
+			json, jsonErr = FormatPact(json)
+		}
 		if jsonErr != nil {
-			return fmt.Errorf("couldn't change interaction to test case - interaction idx: %d err: %w", idx, jsonErr)
+			return nil, fmt.Errorf("couldn't change interaction to test case - interaction idx: %d err: %w", idx, jsonErr)
 		}
 
-		description := sanitize(tc.Interactions[0].Description)
-		tcFilePath := filepath.Join(outputDirPath, description+".json")
+		tcFilePath := filepath.Join(outputDirPath, entry.File)
 		if writeErr := os.WriteFile(tcFilePath, json, os.ModePerm); writeErr != nil {
-			return fmt.Errorf("couldn't write test case to file - interaction idx: %d , "+
+			return nil, fmt.Errorf("couldn't write test case to file - interaction idx: %d , "+
 				"output file path: %s, err: %w", idx, tcFilePath, writeErr)
 		}
+		index.Files = append(index.Files, entry)
+	}
+
+	if indexPath != "" {
+		json, err := marshalPactDocument(index)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(indexPath, json, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("couldn't write split index '%s': %w", indexPath, err)
+		}
+	}
+	return index, nil
+}
+
+type interactionGroup struct {
+	name    string
+	members []int
+}
+
+func groupInteractions(interactions []map[string]interface{}, bulkFilePath string, options SplitOptions) []interactionGroup {
+	var groups []interactionGroup
+	switch options.GroupBy {
+	case SplitInChunks:
+		base := strings.TrimSuffix(filepath.Base(bulkFilePath), filepath.Ext(bulkFilePath))
+		width := len(strconv.Itoa((len(interactions) + options.ChunkSize - 1) / options.ChunkSize))
+		for start := 0; start < len(interactions); start += options.ChunkSize {
+			group := interactionGroup{name: fmt.Sprintf("%s-%0*d", base, width, start/options.ChunkSize+1)}
+			for i := start; i < start+options.ChunkSize && i < len(interactions); i++ {
+				group.members = append(group.members, i)
+			}
+			groups = append(groups, group)
+		}
+	case SplitPerProviderState, SplitPerRequestPath:
+		positions := make(map[string]int)
+		for i, interaction := range interactions {
+			key, name := providerStateKey(interaction), strings.Join(providerStateNames(interaction), " and ")
+			if name == "" {
+				name = "no provider state"
+			}
+			if options.GroupBy == SplitPerRequestPath {
+				request, _ := interaction["request"].(map[string]interface{})
+				path, _ := request["path"].(string)
+				key, name = path, strings.ReplaceAll(strings.Trim(path, "/"), "/", ".")
+				if name == "" {
+					name = "root"
+				}
+			}
+			pos, ok := positions[key]
+			if !ok {
+				pos = len(groups)
+				positions[key] = pos
+				groups = append(groups, interactionGroup{name: name})
+			}
+			groups[pos].members = append(groups[pos].members, i)
+		}
+	default:
+		for i, interaction := range interactions {
+			description, _ := interaction["description"].(string)
+			groups = append(groups, interactionGroup{name: description, members: []int{i}})
+		}
+	}
+	return groups
+}
+
+func splitIndexInteraction(index int, interaction map[string]interface{}) SplitIndexInteraction {
+	description, _ := interaction["description"].(string)
+	request, _ := interaction["request"].(map[string]interface{})
+	method, _ := request["method"].(string)
+	path, _ := request["path"].(string)
+	return SplitIndexInteraction{
+		Index:          index,
+		Description:    description,
+		ProviderStates: providerStateNames(interaction),
+		Method:         method,
+		Path:           path,
 	}
-	return nil
 }
 
+// uniqueFileNames hands out sanitised *.json file names, adding a numeric suffix to names that were
+// already used. Names are compared case-insensitively to stay unique on case-insensitive filesystems.
+type uniqueFileNames struct {
+	used map[string]bool
+}
+
+func newUniqueFileNames() *uniqueFileNames {
+	return &uniqueFileNames{used: make(map[string]bool)}
+}
+
+func (u *uniqueFileNames) reserve(fileName string) {
+	u.used[strings.ToLower(fileName)] = true
+}
+
+func (u *uniqueFileNames) next(name string) string {
+	base := sanitize(name)
+	if base == "" {
+		base = "interaction"
+	}
+	fileName := base + ".json"
+	for n := 2; u.used[strings.ToLower(fileName)]; n++ {
+		fileName = base + "-" + strconv.Itoa(n) + ".json"
+	}
+	u.reserve(fileName)
+	return fileName
+}
+
+// maxFileNameLength keeps generated names, including a collision suffix and extension, within the
+// 255 byte limit of common filesystems.
+const maxFileNameLength = 200
+
+// sanitize makes value usable as a file name: path separators are dropped, other characters
+// that are invalid on common filesystems are replaced by '_', and leading/trailing dots and spaces are trimmed.
 func sanitize(value string) string {
-	value = strings.ReplaceAll(value, string(os.PathSeparator), "")
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == '\\' || r == os.PathSeparator:
+			return -1
+		case strings.ContainsRune(`<>:"|?*`, r) || unicode.IsControl(r):
+			return '_'
+		default:
+			return r
+		}
+	}, value)
+	value = strings.Trim(value, " .")
+	if len(value) > maxFileNameLength {
+		value = strings.ToValidUTF8(value[:maxFileNameLength], "")
+	}
 
 	return value
 }
@@ -0,0 +1,183 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pactSplitStage struct {
+	t         *testing.T
+	bulkFile  string
+	outputDir string
+	options   SplitOptions
+	filters   []PactRequestMatchingFilter
+	index     *SplitIndex
+	split     []map[string]interface{}
+}
+
+func PactSplitTest(t *testing.T) (*pactSplitStage, *pactSplitStage, *pactSplitStage) {
+	t.Helper()
+	s := &pactSplitStage{t: t, outputDir: t.TempDir()}
+	return s, s, s
+}
+
+func (s *pactSplitStage) and() *pactSplitStage {
+	return s
+}
+
+func (s *pactSplitStage) a_bulk_pact_file(path string) *pactSplitStage {
+	s.bulkFile = path
+	return s
+}
+
+func (s *pactSplitStage) the_file_is_split(options SplitOptions) *pactSplitStage {
+	options.RequestFilters = append(options.RequestFilters, s.filters...)
+	s.options = options
+	index, err := SplitPactFile(s.bulkFile, s.outputDir, options)
+	require.NoError(s.t, err)
+	s.index = index
+	return s
+}
+
+func (s *pactSplitStage) files_are_written(names ...string) *pactSplitStage {
+	files, err := filepath.Glob(filepath.Join(s.outputDir, "*.json"))
+	require.NoError(s.t, err)
+	written := make([]string, 0, len(files))
+	for _, f := range files {
+		written = append(written, filepath.Base(f))
+	}
+	assert.ElementsMatch(s.t, names, written)
+	return s
+}
+
+func (s *pactSplitStage) each_file_keeps_the_metadata() *pactSplitStage {
+	for _, entry := range s.index.Files {
+		data, err := os.ReadFile(filepath.Join(s.outputDir, entry.File))
+		require.NoError(s.t, err)
+		pactFile, err := NewPactFile(data)
+		require.NoError(s.t, err)
+		assert.Equal(s.t, "testservicea", pactFile.Provider.Name)
+		assert.Equal(s.t, map[string]interface{}{
+			"pactSpecification": map[string]interface{}{"version": "2.0.0"},
+		}, pactFile.Metadata)
+	}
+	return s
+}
+
+func (s *pactSplitStage) the_index_maps_file_to_interactions(file string, indices ...int) *pactSplitStage {
+	index := s.index
+	if s.options.IndexPath != "" {
+		data, err := os.ReadFile(filepath.Join(s.outputDir, s.options.IndexPath))
+		require.NoError(s.t, err)
+		index = &SplitIndex{}
+		require.NoError(s.t, json.Unmarshal(data, index))
+	}
+	for _, entry := range index.Files {
+		if entry.File != file {
+			continue
+		}
+		actual := make([]int, 0, len(entry.Interactions))
+		for _, i := range entry.Interactions {
+			actual = append(actual, i.Index)
+		}
+		assert.Equal(s.t, indices, actual)
+		return s
+	}
+	s.t.Errorf("index has no entry for %s: %+v", file, index.Files)
+	return s
+}
+
+func (s *pactSplitStage) a_request_filter_removing(metaDataKey string) *pactSplitStage {
+	s.filters = append(s.filters, func(message map[string]interface{}) {
+		if metaData, ok := message["metaData"].(map[string]interface{}); ok {
+			delete(metaData, metaDataKey)
+		}
+	})
+	return s
+}
+
+func (s *pactSplitStage) the_split_message_keeps_its_fields(file, id string) *pactSplitStage {
+	data, err := os.ReadFile(filepath.Join(s.outputDir, file))
+	require.NoError(s.t, err)
+	assert.Contains(s.t, string(data), id, "large numbers must not lose precision")
+
+	pactFile, err := NewPactFile(data)
+	require.NoError(s.t, err)
+	assert.Empty(s.t, pactFile.Interactions)
+	require.Len(s.t, pactFile.Messages, 1)
+	message := pactFile.Messages[0]
+	assert.Equal(s.t, "message 1", message.Description)
+	assert.Equal(s.t, []interface{}{map[string]interface{}{
+		"name":   "a payment was submitted",
+		"params": map[string]interface{}{"id": "p1"},
+	}}, message.ProviderStates)
+	assert.NotNil(s.t, message.MatchingRules)
+	assert.Equal(s.t, map[string]interface{}{
+		"pactSpecification": map[string]interface{}{"version": "3.0.0"},
+	}, pactFile.Metadata)
+	return s
+}
+
+func (s *pactSplitStage) the_filter_was_applied_to_the_messages(metaDataKey string) *pactSplitStage {
+	for _, entry := range s.index.Files {
+		data, err := os.ReadFile(filepath.Join(s.outputDir, entry.File))
+		require.NoError(s.t, err)
+		pactFile, err := NewPactFile(data)
+		require.NoError(s.t, err)
+		for _, message := range pactFile.Messages {
+			assert.NotContains(s.t, message.MetaData, metaDataKey)
+			assert.Contains(s.t, message.MetaData, "contentType")
+		}
+	}
+	return s
+}
+
+func (s *pactSplitStage) each_file_is_formatted() *pactSplitStage {
+	for _, entry := range s.index.Files {
+		data, err := os.ReadFile(filepath.Join(s.outputDir, entry.File))
+		require.NoError(s.t, err)
+		formatted, err := FormatPact(data)
+		require.NoError(s.t, err)
+		assert.Equal(s.t, string(formatted), string(data), entry.File)
+	}
+	return s
+}
+
+func (s *pactSplitStage) the_pact_file_is_split_in_memory() *pactSplitStage {
+	data, err := os.ReadFile(s.bulkFile)
+	require.NoError(s.t, err)
+	pactFile, err := NewPactFile(data)
+	require.NoError(s.t, err)
+	for _, single := range *pactFile.Split() {
+		encoded, err := json.Marshal(single)
+		require.NoError(s.t, err)
+		var decoded map[string]interface{}
+		require.NoError(s.t, json.Unmarshal(encoded, &decoded))
+		s.split = append(s.split, decoded)
+	}
+	return s
+}
+
+func (s *pactSplitStage) each_split_message_keeps_its_provider_state(states ...string) *pactSplitStage {
+	require.Len(s.t, s.split, len(states))
+	for i, state := range states {
+		messages, _ := s.split[i]["messages"].([]interface{})
+		require.Len(s.t, messages, 1)
+		message, _ := messages[0].(map[string]interface{})
+		assert.Equal(s.t, state, message["providerState"])
+		assert.NotContains(s.t, message, "providerStates")
+	}
+	return s
+}
+
+func (s *pactSplitStage) the_split_files_have_no_interactions_key() *pactSplitStage {
+	for _, single := range s.split {
+		assert.NotContains(s.t, single, "interactions")
+	}
+	return s
+}
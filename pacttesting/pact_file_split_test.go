@@ -0,0 +1,123 @@
+package pacttesting
+
+import "testing"
+
+func TestSplit_PerInteraction_NamesAreUniqueAndSafe(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/testservices.states.bulk.json")
+
+	when.
+		the_file_is_split(SplitOptions{IndexPath: "index.manifest"})
+
+	then.
+		files_are_written(
+			"Request for a test endpoint.json",
+			"Request for a test endpoint-2.json",
+			"Request for another test endpoint_ _B__.json",
+		).and().
+		each_file_keeps_the_metadata().and().
+		the_index_maps_file_to_interactions("Request for a test endpoint-2.json", 1)
+}
+
+func TestSplit_PerProviderState(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/testservices.states.bulk.json")
+
+	when.
+		the_file_is_split(SplitOptions{GroupBy: SplitPerProviderState})
+
+	then.
+		files_are_written("endpoint exists.json", "endpoint does not exist.json").and().
+		the_index_maps_file_to_interactions("endpoint exists.json", 0, 2)
+}
+
+func TestSplit_PerRequestPath(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/testservices.states.bulk.json")
+
+	when.
+		the_file_is_split(SplitOptions{GroupBy: SplitPerRequestPath})
+
+	then.
+		files_are_written("v1.test.json", "v1.other.json").and().
+		the_index_maps_file_to_interactions("v1.test.json", 0, 1)
+}
+
+func TestSplit_InChunks(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/testservices.states.bulk.json")
+
+	when.
+		the_file_is_split(SplitOptions{GroupBy: SplitInChunks, ChunkSize: 2})
+
+	then.
+		files_are_written("testservices.states.bulk-1.json", "testservices.states.bulk-2.json").and().
+		the_index_maps_file_to_interactions("testservices.states.bulk-1.json", 0, 1).and().
+		the_index_maps_file_to_interactions("testservices.states.bulk-2.json", 2)
+}
+
+func TestSplit_MessagePact_PerInteraction(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/messages.bulk.json").and().
+		a_request_filter_removing("queue")
+
+	when.
+		the_file_is_split(SplitOptions{})
+
+	then.
+		files_are_written("message 1.json", "message 2.json").and().
+		the_split_message_keeps_its_fields("message 1.json", "5577006791947779410").and().
+		the_filter_was_applied_to_the_messages("queue")
+}
+
+func TestSplit_MessagePact_PerProviderState(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/messages.bulk.json")
+
+	when.
+		the_file_is_split(SplitOptions{GroupBy: SplitPerProviderState})
+
+	then.
+		files_are_written("a payment was submitted.json").and().
+		the_index_maps_file_to_interactions("a payment was submitted.json", 0, 1)
+}
+
+func TestSplit_PactFileKeepsV2MessageProviderStates(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/messages.v2.json")
+
+	when.
+		the_pact_file_is_split_in_memory()
+
+	then.
+		each_split_message_keeps_its_provider_state("a payment was submitted", "a payment was validated").and().
+		the_split_files_have_no_interactions_key()
+}
+
+func TestSplit_OutputCanBeFormatted(t *testing.T) {
+	given, when, then := PactSplitTest(t)
+
+	given.
+		a_bulk_pact_file("splitpacts/testservices.states.bulk.json")
+
+	when.
+		the_file_is_split(SplitOptions{GroupBy: SplitPerProviderState, Format: true})
+
+	then.
+		files_are_written("endpoint exists.json", "endpoint does not exist.json").and().
+		each_file_is_formatted()
+}
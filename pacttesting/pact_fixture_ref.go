@@ -0,0 +1,198 @@
+package pacttesting
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// fixtureRefKey marks an object in a fixture as a reference to a shared fragment:
+//
+//	{"$ref": "fragments/organisation.json"}                 the whole file
+//	{"$ref": "fragments/common.json#/responses/notFound"}   a JSON pointer into another file
+//	{"$ref": "#/definitions/organisation", "status": 201}   a JSON pointer into the same file, with an override
+//
+// Files are resolved relative to the file holding the reference and may be JSON or YAML. Keys next to
+// $ref shallowly override the keys of the referenced object. A key that really is $ref, e.g. in a JSON Schema the
+// provider serves, is written as $$ref and kept as a literal $ref.
+const fixtureRefKey = "$ref"
+
+// escapedFixtureRefKey is the key written in fixtures for a literal $ref key.
+const escapedFixtureRefKey = "$" + fixtureRefKey
+
+// FixtureRefCycleError is returned when fixture references refer back to themselves.
+type FixtureRefCycleError struct {
+	// Chain lists the references that form the cycle, ending with the one that closes it.
+	Chain []string
+}
+
+func (e *FixtureRefCycleError) Error() string {
+	return "fixture $ref cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// refResolver replaces $ref objects with the fragments they refer to, reading fragment files through a PactLoader.
+type refResolver struct {
+	loader *PactLoader
+	// files caches decoded fixture files by their slash separated name relative to the loader directory.
+	files map[string]interface{}
+	// stack holds the references currently being resolved, to detect cycles.
+	stack []string
+}
+
+// resolveFixtureRefs returns doc, read from file, with all references resolved.
+func (l *PactLoader) resolveFixtureRefs(file string, doc interface{}) (interface{}, error) {
+	r := &refResolver{loader: l, files: map[string]interface{}{file: doc}}
+	return r.resolve(doc, file, jsonPath{})
+}
+
+// resolve returns a copy of v with references resolved. file is the fixture v was read from and location the
+// position in the root document that errors are reported at.
+func (r *refResolver) resolve(v interface{}, file string, location jsonPath) (interface{}, error) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := value[fixtureRefKey]; ok {
+			return r.resolveRef(value, ref, file, location)
+		}
+		result := make(map[string]interface{}, len(value))
+		for k, child := range value {
+			resolved, err := r.resolve(child, file, location.key(k))
+			if err != nil {
+				return nil, err
+			}
+			result[unescapeFixtureRefKey(k)] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, child := range value {
+			resolved, err := r.resolve(child, file, location.index(i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	}
+	return v, nil
+}
+
+func (r *refResolver) resolveRef(value map[string]interface{}, ref interface{}, file string, location jsonPath) (interface{}, error) {
+	target, ok := ref.(string)
+	if !ok {
+		return nil, documentErrorf(location.key(fixtureRefKey), "%s must be a string", fixtureRefKey)
+	}
+	targetFile, pointer, _ := strings.Cut(target, "#")
+	if targetFile == "" {
+		targetFile = file
+	} else {
+		targetFile = path.Join(path.Dir(file), targetFile)
+	}
+
+	key := targetFile + "#" + pointer
+	for i, entry := range r.stack {
+		if entry == key {
+			chain := append(append([]string{}, r.stack[i:]...), key)
+			return nil, &documentError{location: location, err: &FixtureRefCycleError{Chain: chain}}
+		}
+	}
+	r.stack = append(r.stack, key)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	fragment, err := r.file(targetFile)
+	if err != nil {
+		return nil, &documentError{location: location, err: err}
+	}
+	node, err := resolveJSONPointer(fragment, pointer)
+	if err != nil {
+		return nil, documentErrorf(location, "%s %q: %w", fixtureRefKey, target, err)
+	}
+	resolved, err := r.resolve(node, targetFile, location)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) == 1 {
+		return resolved, nil
+	}
+
+	base, ok := resolved.(map[string]interface{})
+	if !ok {
+		return nil, documentErrorf(location, "%s %q does not refer to an object, so it cannot be overridden", fixtureRefKey, target)
+	}
+	result := make(map[string]interface{}, len(base)+len(value)-1)
+	for k, child := range base {
+		result[k] = child
+	}
+	for k, child := range value {
+		if k == fixtureRefKey {
+			continue
+		}
+		override, err := r.resolve(child, file, location.key(k))
+		if err != nil {
+			return nil, err
+		}
+		result[unescapeFixtureRefKey(k)] = override
+	}
+	return result, nil
+}
+
+// unescapeFixtureRefKey returns the key a fixture key stands for: $ref for $$ref, and the key itself otherwise.
+func unescapeFixtureRefKey(key string) string {
+	if key == escapedFixtureRefKey {
+		return fixtureRefKey
+	}
+	return key
+}
+
+// file returns the decoded content of a fixture file, reading it on first use.
+func (r *refResolver) file(name string) (interface{}, error) {
+	if doc, ok := r.files[name]; ok {
+		return doc, nil
+	}
+	data, location, err := r.loader.readFixture(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading fragment: %w", err)
+	}
+	trackFixtureRead(location)
+	if isYAMLFile(name) {
+		if data, err = ConvertYAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("converting fragment '%s': %w", location, err)
+		}
+	}
+	doc, err := decodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing fragment '%s': %w", location, err)
+	}
+	r.files[name] = doc
+	return doc, nil
+}
+
+// resolveJSONPointer returns the node of v that an RFC 6901 JSON pointer such as /responses/0/body refers to.
+func resolveJSONPointer(v interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return v, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON pointer %q must start with /", pointer)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("JSON pointer %q: no member %q", pointer, token)
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("JSON pointer %q: no element %q", pointer, token)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("JSON pointer %q: %q is not inside an object or array", pointer, token)
+		}
+	}
+	return v, nil
+}
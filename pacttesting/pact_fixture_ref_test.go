@@ -0,0 +1,92 @@
+package pacttesting
+
+import "testing"
+
+func TestFixtureRef_FragmentsAreResolved(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts")
+
+	when.
+		the_pact_is_loaded("testservicea.get.refs.test")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_provider_state(0, "organisation exists").and().
+		the_response_has_status(0, 200).and().
+		the_response_has_header(0, "Content-Type", "application/json; charset=utf-8").and().
+		the_response_body_has("id", "743d5b63-5e1a-4c89-9c05-0b7e3f5f6a1d")
+}
+
+func TestFixtureRef_KeysNextToRefOverrideTheFragment(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts")
+
+	when.
+		the_pact_is_loaded("testservicea.get.refs.test")
+
+	then.
+		no_error_is_returned().and().
+		the_response_has_status(1, 201).and().
+		the_response_has_header(1, "Content-Type", "application/json; charset=utf-8")
+}
+
+func TestFixtureRef_CyclesAreAnError(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("lintpacts/refcycle")
+
+	when.
+		the_pact_is_loaded("a")
+
+	then.
+		a_reference_cycle_is_reported("b.json#/response", "b.json#/response")
+}
+
+func TestFixtureRef_ResolvedPactHasNoReferences(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts")
+
+	when.
+		the_pact_is_resolved("testservicea.get.refs.test")
+
+	then.
+		no_error_is_returned().and().
+		the_resolved_pact_does_not_contain(`"$ref"`)
+}
+
+func TestFixtureRef_RefsInBodiesAreResolved(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts")
+
+	when.
+		the_pact_is_loaded("testservicea.get.bodyref.test")
+
+	then.
+		no_error_is_returned().and().
+		the_response_body_has("id", "743d5b63-5e1a-4c89-9c05-0b7e3f5f6a1d").and().
+		the_response_body_has("name", "renamed organisation")
+}
+
+func TestFixtureRef_EscapedRefsAreKeptLiterally(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts")
+
+	when.
+		the_pact_is_loaded("testservicea.get.schema.test")
+
+	then.
+		no_error_is_returned().and().
+		the_response_has_header(0, "Content-Type", "application/json; charset=utf-8").and().
+		the_response_body_has("$ref", "#/definitions/organisation")
+}
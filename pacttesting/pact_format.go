@@ -0,0 +1,247 @@
+package pacttesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// formatPactsEnv enables formatting of the pacts the mock service writes to target/, see formatVerifiedPacts.
+const formatPactsEnv = "PACTTESTING_FORMAT_PACTS"
+
+// fieldOrders lists the keys that come first, in this order, in the objects of a formatted pact. Any other
+// keys follow in alphabetical order. Objects are identified by their position, e.g. "interactions[].request".
+//
+//nolint:gochecknoglobals // constant lookup table
+var fieldOrders = map[string][]string{
+	"":                        {"consumer", "provider", "interactions", "messages", "metadata"},
+	"interactions[]":          {"description", "providerState", "providerStates", "request", "response"},
+	"interactions[].request":  {"method", "path", "query", "headers", "body", "matchingRules", "generators"},
+	"interactions[].response": {"status", "headers", "body", "matchingRules", "generators"},
+	"messages[]":              {"description", "providerState", "providerStates", "contents", "metaData", "matchingRules"},
+}
+
+// FormatPact returns a pact in canonical form, so that equal pacts are byte for byte equal and diffs between
+// versions only show real changes:
+//   - interactions and messages are sorted by description and provider state;
+//   - well-known pact fields come in their conventional order, all other keys are sorted;
+//   - matching rule paths are normalised, e.g. $.body['id'] and $.body.items.* become $.body.id and $.body.items[*];
+//   - indentation is two spaces, HTML characters are not escaped and numbers are kept as written.
+func FormatPact(data []byte) ([]byte, error) {
+	doc, err := parsePactDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range pactInteractionFields {
+		items, ok := doc[field].([]interface{})
+		if !ok {
+			continue
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			a, _ := items[i].(map[string]interface{})
+			b, _ := items[j].(map[string]interface{})
+			return interactionKey(a) < interactionKey(b)
+		})
+		for i, item := range documentInteractions(doc, field) {
+			owners := []map[string]interface{}{item}
+			if field == "interactions" {
+				request, _ := item["request"].(map[string]interface{})
+				response, _ := item["response"].(map[string]interface{})
+				owners = []map[string]interface{}{request, response}
+			}
+			for _, owner := range owners {
+				if err := normaliseMatcherPaths(owner); err != nil {
+					return nil, fmt.Errorf("%s: %w", jsonPath{field, i}, err)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeFormatted(&buf, doc, "", ""); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// FormatPactFiles rewrites the given pact files in canonical form and returns the ones that changed.
+func FormatPactFiles(paths ...string) ([]string, error) {
+	var changed []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return changed, fmt.Errorf("reading pact file '%s': %w", path, err)
+		}
+		formatted, err := FormatPact(data)
+		if err != nil {
+			return changed, fmt.Errorf("formatting pact file '%s': %w", path, err)
+		}
+		if bytes.Equal(data, formatted) {
+			continue
+		}
+		if err := os.WriteFile(path, formatted, 0o600); err != nil {
+			return changed, fmt.Errorf("writing pact file '%s': %w", path, err)
+		}
+		changed = append(changed, path)
+	}
+	return changed, nil
+}
+
+// formatVerifiedPacts formats the pacts of IntegrationTest and RunIntegrationTest once they are verified, if that is
+// enabled through PACTTESTING_FORMAT_PACTS. The mock service only writes its pact when it shuts down, so it is first
+// asked to write it now. A later write of a still running mock service, e.g. when it shuts down, merges into the
+// formatted file without formatting it, which StopMockServers catches up on.
+func formatVerifiedPacts(pacts []*pact) {
+	if !formatPactsEnabled() {
+		return
+	}
+	for _, p := range pacts {
+		server, ok := pactServers[p.Provider.Name+p.Consumer.Name]
+		if !ok {
+			continue
+		}
+		if err := server.WritePact(); err != nil {
+			log.WithError(err).Warnf("unable to write pact for consumer(%s), provider(%s)", server.Consumer, server.Provider)
+			continue
+		}
+		formatWrittenPact(server.Consumer, server.Provider)
+	}
+}
+
+func formatPactsEnabled() bool {
+	return os.Getenv(formatPactsEnv) == "true"
+}
+
+// formatWrittenPact formats the pact the mock service wrote for a consumer and provider if that is enabled
+// through PACTTESTING_FORMAT_PACTS.
+func formatWrittenPact(consumer, provider string) {
+	if !formatPactsEnabled() {
+		return
+	}
+	dir, _ := os.Getwd()
+	file := filepath.Join(dir, "target", pactFileName(consumer, provider))
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if _, err := FormatPactFiles(file); err != nil {
+		log.WithError(err).Warnf("unable to format pact file %s", file)
+	}
+}
+
+// normaliseMatcherPaths rewrites the JSONPath keys of the matching rules of a request, response or message
+// in the form formatMatcherPath produces.
+func normaliseMatcherPaths(owner map[string]interface{}) error {
+	rules, _ := owner["matchingRules"].(map[string]interface{})
+	if isV2MatchingRules(rules) {
+		normalised, err := normalisePathKeys(rules)
+		if err != nil {
+			return err
+		}
+		owner["matchingRules"] = normalised
+		return nil
+	}
+	if body, ok := rules["body"].(map[string]interface{}); ok {
+		normalised, err := normalisePathKeys(body)
+		if err != nil {
+			return err
+		}
+		rules["body"] = normalised
+	}
+	return nil
+}
+
+func normalisePathKeys(entries map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(entries))
+	for _, expr := range sortedKeys(entries) {
+		key := expr
+		if segments, err := parseMatcherPath(expr); err == nil {
+			key = formatMatcherPath(segments)
+		}
+		if _, ok := result[key]; ok {
+			return nil, fmt.Errorf("matching rules %s and another path both normalise to %s", expr, key)
+		}
+		result[key] = entries[expr]
+	}
+	return result, nil
+}
+
+// writeFormatted writes v as indented JSON. position identifies v for fieldOrders.
+func writeFormatted(buf *bytes.Buffer, v interface{}, position, indent string) error {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i, key := range orderedKeys(value, fieldOrders[position]) {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(indent + "  ")
+			if err := writeScalar(buf, key); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			childPosition := strings.TrimPrefix(position+"."+key, ".")
+			if err := writeFormatted(buf, value[key], childPosition, indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+	case []interface{}:
+		if len(value) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range value {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(indent + "  ")
+			if err := writeFormatted(buf, item, position+"[]", indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+	default:
+		return writeScalar(buf, value)
+	}
+	return nil
+}
+
+func writeScalar(buf *bytes.Buffer, v interface{}) error {
+	var scalar bytes.Buffer
+	encoder := json.NewEncoder(&scalar)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("encoding pact: %w", err)
+	}
+	buf.Write(bytes.TrimSuffix(scalar.Bytes(), []byte("\n")))
+	return nil
+}
+
+// orderedKeys returns the keys of m: those in order first, the rest sorted.
+func orderedKeys(m map[string]interface{}, order []string) []string {
+	keys := make([]string, 0, len(m))
+	for _, key := range order {
+		if _, ok := m[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range sortedKeys(m) {
+		if !containsString(order, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,183 @@
+package pacttesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pactFormatStage struct {
+	t       *testing.T
+	content []byte
+	result  []byte
+	dir     string
+	copies  []string
+	changed []string
+	err     error
+	server  *MockServer
+	writes  int
+}
+
+func PactFormatTest(t *testing.T) (*pactFormatStage, *pactFormatStage, *pactFormatStage) {
+	t.Helper()
+	s := &pactFormatStage{t: t}
+	return s, s, s
+}
+
+func (s *pactFormatStage) and() *pactFormatStage {
+	return s
+}
+
+func (s *pactFormatStage) a_pact_file(path string) *pactFormatStage {
+	content, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	s.content = content
+	return s
+}
+
+func (s *pactFormatStage) pact_content(content string) *pactFormatStage {
+	s.content = []byte(content)
+	return s
+}
+
+func (s *pactFormatStage) a_copy_of_pact_files(paths ...string) *pactFormatStage {
+	s.dir = s.t.TempDir()
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(s.t, err)
+		target := filepath.Join(s.dir, filepath.Base(path))
+		require.NoError(s.t, os.WriteFile(target, content, 0o600))
+		s.copies = append(s.copies, target)
+	}
+	return s
+}
+
+func (s *pactFormatStage) the_pact_is_formatted() *pactFormatStage {
+	s.result, s.err = FormatPact(s.content)
+	return s
+}
+
+func (s *pactFormatStage) the_pact_files_are_formatted() *pactFormatStage {
+	s.changed, s.err = FormatPactFiles(s.copies...)
+	return s
+}
+
+func (s *pactFormatStage) no_format_error_is_returned() *pactFormatStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *pactFormatStage) a_format_error_is_returned() *pactFormatStage {
+	assert.Error(s.t, s.err)
+	return s
+}
+
+func (s *pactFormatStage) the_result_equals_file(path string) *pactFormatStage {
+	expected, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	assert.Equal(s.t, string(expected), string(s.result))
+	return s
+}
+
+func (s *pactFormatStage) only_the_files_changed(names ...string) *pactFormatStage {
+	expected := make([]string, len(names))
+	for i, name := range names {
+		expected[i] = filepath.Join(s.dir, name)
+	}
+	assert.Equal(s.t, expected, s.changed)
+	return s
+}
+
+func (s *pactFormatStage) the_copy_equals_file(name, path string) *pactFormatStage {
+	expected, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	actual, err := os.ReadFile(filepath.Join(s.dir, name))
+	require.NoError(s.t, err)
+	assert.Equal(s.t, string(expected), string(actual))
+	return s
+}
+
+func (s *pactFormatStage) a_mock_service_writing_pact(path string) *pactFormatStage {
+	content, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	cwd, err := os.Getwd()
+	require.NoError(s.t, err)
+	server := &MockServer{Consumer: "go-pact-testing", Provider: "testserviceformatted", Running: true}
+	file := filepath.Join(cwd, "target", pactFileName(server.Consumer, server.Provider))
+	// the mock service accepts interactions and verifies them, and writes content as its pact on POST /pact
+	mockService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pact-Mock-Service") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/pact" {
+			return
+		}
+		s.writes++
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err == nil {
+			_ = os.WriteFile(file, content, 0o600)
+		}
+	}))
+	server.BaseURL = mockService.URL
+	key := server.Provider + server.Consumer
+	pactServers[key] = server
+	s.server = server
+	s.t.Cleanup(func() {
+		delete(pactServers, key)
+		mockService.Close()
+		_ = os.Remove(file)
+	})
+	return s
+}
+
+func (s *pactFormatStage) formatting_written_pacts_is(enabled string) *pactFormatStage {
+	s.t.Setenv(formatPactsEnv, enabled)
+	return s
+}
+
+func (s *pactFormatStage) the_pacts_are_verified() *pactFormatStage {
+	formatVerifiedPacts([]*pact{{
+		Consumer: pactName{Name: s.server.Consumer},
+		Provider: pactName{Name: s.server.Provider},
+	}})
+	return s
+}
+
+func (s *pactFormatStage) a_fixture_for_the_mock_service() *pactFormatStage {
+	s.dir = s.t.TempDir()
+	fixture := `{"consumer": {"name": "` + s.server.Consumer + `"}, "provider": {"name": "` + s.server.Provider + `"},
+		"interactions": [{"description": "a request", "request": {"method": "GET", "path": "/"}, "response": {"status": 200}}]}`
+	require.NoError(s.t, os.WriteFile(filepath.Join(s.dir, "formatted.json"), []byte(fixture), 0o600))
+	return s
+}
+
+func (s *pactFormatStage) an_integration_test_is_run() *pactFormatStage {
+	require.NoError(s.t, (&PactLoader{Dir: s.dir}).IntegrationTest([]Pact{"formatted"}, func() {}))
+	return s
+}
+
+func (s *pactFormatStage) an_integration_test_is_run_with_testing_t() *pactFormatStage {
+	require.NoError(s.t, (&PactLoader{Dir: s.dir}).RunIntegrationTest(s.t, []Pact{"formatted"}, func() {}))
+	return s
+}
+
+func (s *pactFormatStage) the_mock_service_wrote_the_pact(times int) *pactFormatStage {
+	assert.Equal(s.t, times, s.writes)
+	return s
+}
+
+func (s *pactFormatStage) the_written_pact_equals_file(path string) *pactFormatStage {
+	expected, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	cwd, err := os.Getwd()
+	require.NoError(s.t, err)
+	actual, err := os.ReadFile(filepath.Join(cwd, "target", pactFileName(s.server.Consumer, s.server.Provider)))
+	require.NoError(s.t, err)
+	assert.Equal(s.t, string(expected), string(actual))
+	return s
+}
@@ -0,0 +1,121 @@
+package pacttesting
+
+import "testing"
+
+func TestFormat_PactIsCanonicalised(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		a_pact_file("formatpacts/unformatted.json")
+
+	when.
+		the_pact_is_formatted()
+
+	then.
+		no_format_error_is_returned().and().
+		the_result_equals_file("formatpacts/formatted.json")
+}
+
+func TestFormat_FormattingIsIdempotent(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		a_pact_file("formatpacts/formatted.json")
+
+	when.
+		the_pact_is_formatted()
+
+	then.
+		no_format_error_is_returned().and().
+		the_result_equals_file("formatpacts/formatted.json")
+}
+
+func TestFormat_CollidingMatcherPathsAreAnError(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		pact_content(`{"interactions": [{"description": "a", "request": {"method": "GET", "path": "/"},
+			"response": {"status": 200, "matchingRules": {"$.body.id": {"match": "type"}, "$.body['id']": {"match": "type"}}}}]}`)
+
+	when.
+		the_pact_is_formatted()
+
+	then.
+		a_format_error_is_returned()
+}
+
+func TestFormat_FilesAreRewrittenInPlace(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		a_copy_of_pact_files("formatpacts/unformatted.json", "formatpacts/formatted.json")
+
+	when.
+		the_pact_files_are_formatted()
+
+	then.
+		no_format_error_is_returned().and().
+		only_the_files_changed("unformatted.json").and().
+		the_copy_equals_file("unformatted.json", "formatpacts/formatted.json")
+}
+
+func TestFormat_VerifiedPactsAreWrittenAndFormatted(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		a_mock_service_writing_pact("formatpacts/unformatted.json").and().
+		formatting_written_pacts_is("true")
+
+	when.
+		the_pacts_are_verified()
+
+	then.
+		the_mock_service_wrote_the_pact(1).and().
+		the_written_pact_equals_file("formatpacts/formatted.json")
+}
+
+func TestFormat_IntegrationTestFormatsVerifiedPacts(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		a_mock_service_writing_pact("formatpacts/unformatted.json").and().
+		a_fixture_for_the_mock_service().and().
+		formatting_written_pacts_is("true")
+
+	when.
+		an_integration_test_is_run()
+
+	then.
+		the_mock_service_wrote_the_pact(1).and().
+		the_written_pact_equals_file("formatpacts/formatted.json")
+}
+
+func TestFormat_RunIntegrationTestFormatsVerifiedPacts(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		a_mock_service_writing_pact("formatpacts/unformatted.json").and().
+		a_fixture_for_the_mock_service().and().
+		formatting_written_pacts_is("true")
+
+	when.
+		an_integration_test_is_run_with_testing_t()
+
+	then.
+		the_mock_service_wrote_the_pact(1).and().
+		the_written_pact_equals_file("formatpacts/formatted.json")
+}
+
+func TestFormat_VerifiedPactsAreLeftAloneUnlessEnabled(t *testing.T) {
+	given, when, then := PactFormatTest(t)
+
+	given.
+		a_mock_service_writing_pact("formatpacts/unformatted.json").and().
+		formatting_written_pacts_is("")
+
+	when.
+		the_pacts_are_verified()
+
+	then.
+		the_mock_service_wrote_the_pact(0)
+}
@@ -0,0 +1,672 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity classifies a Diagnostic reported by Lint.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Lint rules reported in Diagnostic.Rule.
+const (
+	RuleSyntax               = "syntax"
+	RuleSchema               = "schema"
+	RuleMissingProvider      = "missing-provider"
+	RuleMissingConsumer      = "missing-consumer"
+	RuleDuplicateDescription = "duplicate-description"
+	RuleStateCollision       = "state-collision"
+	RuleDanglingMatcher      = "dangling-matcher"
+	RuleUnknownMatcher       = "unknown-matcher"
+	RuleSpecMetadata         = "spec-metadata"
+	RuleMatcherShorthand     = "matcher-shorthand"
+	RuleFixtureRef           = "fixture-ref"
+)
+
+// Diagnostic describes a single problem found in a pact file, positioned at the offending JSON node.
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s at %s]", d.File, d.Line, d.Column, d.Severity, d.Message, d.Rule, d.Path)
+}
+
+// ValidationError is returned by Validate when at least one pact file has error diagnostics.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		lines = append(lines, d.String())
+	}
+	return "invalid pact files:\n" + strings.Join(lines, "\n")
+}
+
+// HasErrors reports whether any of the diagnostics is an error rather than a warning.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks the content of a single pact file and returns every problem found, sorted by position.
+// file is only used to label the diagnostics.
+func Lint(file string, data []byte) []Diagnostic {
+	l := &pactLinter{file: file}
+	positions, err := indexJSONPositions(data)
+	l.positions = positions
+	if err != nil {
+		l.syntaxError(err)
+		return l.diagnostics
+	}
+	doc, err := decodeJSON(data)
+	if err != nil {
+		l.syntaxError(err)
+		return l.diagnostics
+	}
+	// fixtures may use fragment references and matcher shorthand, which are linted in the form the loader
+	// sends to the mock service
+	loader := &PactLoader{Dir: filepath.Dir(file)}
+	doc, err = loader.resolveFixtureRefs(filepath.Base(file), doc)
+	if l.reportDocumentError(RuleFixtureRef, err) {
+		return l.diagnostics
+	}
+	if pact, ok := doc.(map[string]interface{}); ok && l.reportDocumentError(RuleMatcherShorthand, expandMatcherShorthand(pact)) {
+		return l.diagnostics
+	}
+	l.lintPact(doc)
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		if l.diagnostics[i].Line != l.diagnostics[j].Line {
+			return l.diagnostics[i].Line < l.diagnostics[j].Line
+		}
+		return l.diagnostics[i].Column < l.diagnostics[j].Column
+	})
+	return l.diagnostics
+}
+
+// reportDocumentError reports err under rule if it is positioned in the document, returning whether it did.
+func (l *pactLinter) reportDocumentError(rule string, err error) bool {
+	var docErr *documentError
+	if !errors.As(err, &docErr) {
+		return false
+	}
+	l.report(SeverityError, rule, docErr.location, "%s", docErr.err)
+	return true
+}
+
+// LintPactFiles reads and lints every given pact file.
+func LintPactFiles(paths ...string) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading pact file '%s': %w", path, err)
+		}
+		diagnostics = append(diagnostics, Lint(path, data)...)
+	}
+	return diagnostics, nil
+}
+
+// Validate lints the given pact files and returns a *ValidationError holding all
+// diagnostics if any of them is an error. Warnings alone do not fail validation.
+func Validate(paths ...string) error {
+	diagnostics, err := LintPactFiles(paths...)
+	if err != nil {
+		return err
+	}
+	if HasErrors(diagnostics) {
+		return &ValidationError{Diagnostics: diagnostics}
+	}
+	return nil
+}
+
+//nolint:gochecknoglobals // lookup tables
+var (
+	knownHTTPMethods = map[string]bool{
+		"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+		"DELETE": true, "OPTIONS": true, "TRACE": true, "CONNECT": true,
+	}
+	knownMatcherTypes = map[string]bool{
+		"type": true, "regex": true, "equality": true, "include": true, "integer": true,
+		"decimal": true, "number": true, "timestamp": true, "date": true, "time": true,
+		"null": true, "boolean": true, "contentType": true, "values": true, "semver": true,
+		"arrayContains": true, "statusCode": true, "notEmpty": true, "eachKey": true, "eachValue": true,
+	}
+	v3MatchingRuleCategories = map[string]bool{
+		"body": true, "header": true, "headers": true, "query": true, "path": true,
+		"metadata": true, "metaData": true, "status": true,
+	}
+	specVersionPattern = regexp.MustCompile(`^(\d+)(\.\d+)*$`)
+)
+
+type pactLinter struct {
+	file        string
+	positions   *jsonPositions
+	diagnostics []Diagnostic
+	specMajor   int
+}
+
+func (l *pactLinter) report(severity Severity, rule string, path jsonPath, format string, args ...interface{}) {
+	line, column := l.positions.lookup(path)
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		File:     l.file,
+		Line:     line,
+		Column:   column,
+		Path:     path.String(),
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *pactLinter) syntaxError(err error) {
+	offset := int64(0)
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+	line, column := l.positions.lineColumn(int(offset))
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		File:     l.file,
+		Line:     line,
+		Column:   column,
+		Path:     "$",
+		Severity: SeverityError,
+		Rule:     RuleSyntax,
+		Message:  "invalid JSON: " + err.Error(),
+	})
+}
+
+func (l *pactLinter) lintPact(doc interface{}) {
+	root := jsonPath{}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, root, "pact must be a JSON object")
+		return
+	}
+
+	l.lintParticipant(obj, root, "provider", RuleMissingProvider)
+	l.lintParticipant(obj, root, "consumer", RuleMissingConsumer)
+	l.lintMetadata(obj["metadata"], root.key("metadata"))
+
+	interactions, hasInteractions := obj["interactions"]
+	messages, hasMessages := obj["messages"]
+	switch {
+	case hasInteractions && hasMessages:
+		l.report(SeverityWarning, RuleSchema, root, "pact has both interactions and messages")
+	case !hasInteractions && !hasMessages:
+		l.report(SeverityError, RuleSchema, root, "pact must have an interactions or messages array")
+	}
+	if hasInteractions {
+		l.lintInteractions(interactions, root.key("interactions"), false)
+	}
+	if hasMessages {
+		l.lintInteractions(messages, root.key("messages"), true)
+	}
+}
+
+func (l *pactLinter) lintParticipant(obj map[string]interface{}, root jsonPath, field, rule string) {
+	path := root.key(field)
+	participant, ok := obj[field].(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, rule, path, "%s must be an object with a name", field)
+		return
+	}
+	name, ok := participant["name"].(string)
+	if !ok || strings.TrimSpace(name) == "" {
+		l.report(SeverityError, rule, path.key("name"), "%s name is missing", field)
+	}
+}
+
+func (l *pactLinter) lintMetadata(value interface{}, path jsonPath) {
+	if value == nil {
+		return
+	}
+	metadata, ok := value.(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path, "metadata must be an object")
+		return
+	}
+
+	// pactSpecification is the canonical field; the others are written by older pact implementations.
+	for _, key := range []string{"pactSpecification", "pact-specification", "pactSpecificationVersion"} {
+		value, ok := metadata[key]
+		if !ok {
+			continue
+		}
+		versionPath := path.key(key)
+		if spec, isObject := value.(map[string]interface{}); isObject {
+			value = spec["version"]
+			versionPath = versionPath.key("version")
+		}
+		version, ok := value.(string)
+		if !ok {
+			l.report(SeverityError, RuleSpecMetadata, versionPath, "%s version must be a string", key)
+			continue
+		}
+		m := specVersionPattern.FindStringSubmatch(version)
+		if m == nil {
+			l.report(SeverityError, RuleSpecMetadata, versionPath, "unrecognised pact specification version %q", version)
+			continue
+		}
+		major, _ := strconv.Atoi(m[1])
+		if l.specMajor != 0 && l.specMajor != major {
+			l.report(SeverityError, RuleSpecMetadata, versionPath,
+				"%s declares specification version %s, which contradicts another version in the metadata", key, version)
+			continue
+		}
+		l.specMajor = major
+	}
+}
+
+func (l *pactLinter) lintInteractions(value interface{}, path jsonPath, messages bool) {
+	items, ok := value.([]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path, "%s must be an array", path[len(path)-1])
+		return
+	}
+
+	type seen struct {
+		index int
+		state string
+	}
+	byDescription := map[string][]seen{}
+	for i, item := range items {
+		itemPath := path.index(i)
+		interaction, ok := item.(map[string]interface{})
+		if !ok {
+			l.report(SeverityError, RuleSchema, itemPath, "interaction must be an object")
+			continue
+		}
+		description, ok := interaction["description"].(string)
+		if !ok || strings.TrimSpace(description) == "" {
+			l.report(SeverityError, RuleSchema, itemPath.key("description"), "interaction description is missing")
+		}
+		state := l.lintProviderStates(interaction, itemPath)
+
+		if messages {
+			l.lintMessage(interaction, itemPath)
+		} else {
+			l.lintRequest(interaction["request"], itemPath.key("request"))
+			l.lintResponse(interaction["response"], itemPath.key("response"))
+		}
+
+		if description == "" {
+			continue
+		}
+		collided := false
+		for _, previous := range byDescription[description] {
+			if previous.state == state {
+				l.report(SeverityError, RuleStateCollision, itemPath.key("description"),
+					"interaction %q with the same provider state is already defined at %s",
+					description, path.index(previous.index))
+				collided = true
+				break
+			}
+		}
+		if previous := byDescription[description]; !collided && len(previous) > 0 {
+			l.report(SeverityWarning, RuleDuplicateDescription, itemPath.key("description"),
+				"description %q is also used at %s", description, path.index(previous[0].index))
+		}
+		byDescription[description] = append(byDescription[description], seen{index: i, state: state})
+	}
+}
+
+// lintProviderStates validates providerState/providerStates and returns a key identifying the state(s).
+func (l *pactLinter) lintProviderStates(interaction map[string]interface{}, path jsonPath) string {
+	if value, ok := interaction["providerState"]; ok {
+		if _, isString := value.(string); !isString {
+			l.report(SeverityError, RuleSchema, path.key("providerState"), "providerState must be a string")
+		}
+		if l.specMajor >= 3 {
+			l.report(SeverityWarning, RuleSpecMetadata, path.key("providerState"),
+				"providerState is a v2 field; v%d pacts use providerStates", l.specMajor)
+		}
+		return providerStateKey(interaction)
+	}
+
+	value, ok := interaction["providerStates"]
+	if !ok || value == nil {
+		return ""
+	}
+	statesPath := path.key("providerStates")
+	if l.specMajor != 0 && l.specMajor < 3 {
+		l.report(SeverityError, RuleSpecMetadata, statesPath,
+			"providerStates requires pact specification v3 but metadata declares v%d", l.specMajor)
+	}
+	states, ok := value.([]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, statesPath, "providerStates must be an array")
+		return ""
+	}
+	for i, s := range states {
+		state, ok := s.(map[string]interface{})
+		if !ok {
+			l.report(SeverityError, RuleSchema, statesPath.index(i), "provider state must be an object")
+			continue
+		}
+		name, ok := state["name"].(string)
+		if !ok || name == "" {
+			l.report(SeverityError, RuleSchema, statesPath.index(i).key("name"), "provider state name is missing")
+		}
+		if params, ok := state["params"]; ok && params != nil {
+			if _, ok := params.(map[string]interface{}); !ok {
+				l.report(SeverityError, RuleSchema, statesPath.index(i).key("params"), "provider state params must be an object")
+			}
+		}
+	}
+	return providerStateKey(interaction)
+}
+
+func (l *pactLinter) lintRequest(value interface{}, path jsonPath) {
+	request, ok := value.(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path, "request must be an object")
+		return
+	}
+	method, ok := request["method"].(string)
+	switch {
+	case !ok:
+		l.report(SeverityError, RuleSchema, path.key("method"), "request method is missing")
+	case !knownHTTPMethods[strings.ToUpper(method)]:
+		l.report(SeverityError, RuleSchema, path.key("method"), "unknown request method %q", method)
+	}
+	requestPath, ok := request["path"].(string)
+	switch {
+	case !ok:
+		l.report(SeverityError, RuleSchema, path.key("path"), "request path is missing")
+	case !strings.HasPrefix(requestPath, "/"):
+		l.report(SeverityError, RuleSchema, path.key("path"), "request path %q must start with /", requestPath)
+	}
+	switch query := request["query"].(type) {
+	case nil, string:
+	case map[string]interface{}:
+		if l.specMajor != 0 && l.specMajor < 3 {
+			l.report(SeverityError, RuleSpecMetadata, path.key("query"),
+				"query as an object requires pact specification v3 but metadata declares v%d", l.specMajor)
+		}
+		for _, k := range sortedKeys(query) {
+			if !isStringOrStrings(query[k]) {
+				l.report(SeverityError, RuleSchema, path.key("query").key(k), "query values must be strings or arrays of strings")
+			}
+		}
+	default:
+		l.report(SeverityError, RuleSchema, path.key("query"), "query must be a string or an object")
+	}
+	l.lintHeaders(request["headers"], path.key("headers"))
+	l.lintMatchingRules(request, path)
+}
+
+func (l *pactLinter) lintResponse(value interface{}, path jsonPath) {
+	response, ok := value.(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path, "response must be an object")
+		return
+	}
+	status, ok := response["status"].(json.Number)
+	if !ok {
+		l.report(SeverityError, RuleSchema, path.key("status"), "response status is missing")
+	} else if code, err := status.Int64(); err != nil || code < 100 || code > 599 {
+		l.report(SeverityError, RuleSchema, path.key("status"), "response status %s is not a valid HTTP status", status)
+	}
+	l.lintHeaders(response["headers"], path.key("headers"))
+	l.lintMatchingRules(response, path)
+}
+
+func (l *pactLinter) lintMessage(message map[string]interface{}, path jsonPath) {
+	if metadata, ok := message["metaData"]; ok && metadata != nil {
+		if _, ok := metadata.(map[string]interface{}); !ok {
+			l.report(SeverityError, RuleSchema, path.key("metaData"), "metaData must be an object")
+		}
+	}
+	l.lintMatchingRules(message, path)
+}
+
+func (l *pactLinter) lintHeaders(value interface{}, path jsonPath) {
+	if value == nil {
+		return
+	}
+	headers, ok := value.(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path, "headers must be an object")
+		return
+	}
+	for _, k := range sortedKeys(headers) {
+		if !isStringOrStrings(headers[k]) {
+			l.report(SeverityError, RuleSchema, path.key(k), "header values must be strings")
+		}
+	}
+}
+
+func isStringOrStrings(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return true
+	case []interface{}:
+		for _, s := range v {
+			if _, ok := s.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// lintMatchingRules validates the matchingRules of a request, response or message (the owner),
+// accepting both the flat v2 layout and the categorised v3 layout.
+func (l *pactLinter) lintMatchingRules(owner map[string]interface{}, ownerPath jsonPath) {
+	value, ok := owner["matchingRules"]
+	if !ok || value == nil {
+		return
+	}
+	path := ownerPath.key("matchingRules")
+	rules, ok := value.(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path, "matchingRules must be an object")
+		return
+	}
+	if isV2MatchingRules(rules) {
+		if l.specMajor >= 3 {
+			l.report(SeverityError, RuleSpecMetadata, path,
+				"matchingRules use the v2 layout but metadata declares specification v%d", l.specMajor)
+		}
+		for _, expr := range sortedKeys(rules) {
+			l.lintV2MatchingRule(owner, expr, rules[expr], path.key(expr))
+		}
+		return
+	}
+
+	if l.specMajor != 0 && l.specMajor < 3 {
+		l.report(SeverityError, RuleSpecMetadata, path,
+			"matchingRules use the v3 layout but metadata declares specification v%d", l.specMajor)
+	}
+	for _, category := range sortedKeys(rules) {
+		categoryPath := path.key(category)
+		if !v3MatchingRuleCategories[category] {
+			l.report(SeverityError, RuleSchema, categoryPath, "unknown matching rule category %q", category)
+			continue
+		}
+		entries, ok := rules[category].(map[string]interface{})
+		if !ok {
+			l.report(SeverityError, RuleSchema, categoryPath, "matching rule category must be an object")
+			continue
+		}
+		if category == "path" || category == "status" {
+			l.lintMatcherList(entries, categoryPath)
+			continue
+		}
+		for _, key := range sortedKeys(entries) {
+			entryPath := categoryPath.key(key)
+			entry, ok := entries[key].(map[string]interface{})
+			if !ok {
+				l.report(SeverityError, RuleSchema, entryPath, "matching rule must be an object")
+				continue
+			}
+			l.lintMatcherList(entry, entryPath)
+			l.checkMatcherTarget(owner, category, key, entryPath)
+		}
+	}
+}
+
+func isV2MatchingRules(rules map[string]interface{}) bool {
+	for k := range rules {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return len(rules) > 0
+}
+
+func (l *pactLinter) lintV2MatchingRule(owner map[string]interface{}, expr string, value interface{}, path jsonPath) {
+	rule, ok := value.(map[string]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path, "matching rule must be an object")
+		return
+	}
+	l.lintMatcher(rule, path)
+
+	segments, err := parseMatcherPath(expr)
+	if err != nil {
+		l.report(SeverityError, RuleDanglingMatcher, path, "%s", err)
+		return
+	}
+	if len(segments) == 0 || segments[0].key == nil {
+		l.report(SeverityError, RuleDanglingMatcher, path, "matcher path %q does not name a category", expr)
+		return
+	}
+	category := *segments[0].key
+	switch category {
+	case "body":
+		l.checkBodyPath(owner, segments[1:], expr, path)
+	case "headers", "query":
+		if len(segments) != 2 || segments[1].key == nil {
+			l.report(SeverityError, RuleDanglingMatcher, path, "matcher path %q must name a single %s entry", expr, category)
+			return
+		}
+		l.checkMatcherTarget(owner, category, *segments[1].key, path)
+	case "path", "status":
+	default:
+		l.report(SeverityError, RuleDanglingMatcher, path, "matcher path %q has unknown category %q", expr, category)
+	}
+}
+
+func (l *pactLinter) lintMatcherList(entry map[string]interface{}, path jsonPath) {
+	matchers, ok := entry["matchers"].([]interface{})
+	if !ok {
+		l.report(SeverityError, RuleSchema, path.key("matchers"), "matching rule must have a matchers array")
+		return
+	}
+	if combine, ok := entry["combine"]; ok {
+		if c, _ := combine.(string); c != "AND" && c != "OR" {
+			l.report(SeverityError, RuleSchema, path.key("combine"), "combine must be AND or OR")
+		}
+	}
+	for i, m := range matchers {
+		matcher, ok := m.(map[string]interface{})
+		if !ok {
+			l.report(SeverityError, RuleSchema, path.key("matchers").index(i), "matcher must be an object")
+			continue
+		}
+		l.lintMatcher(matcher, path.key("matchers").index(i))
+	}
+}
+
+func (l *pactLinter) lintMatcher(matcher map[string]interface{}, path jsonPath) {
+	match, hasMatch := matcher["match"]
+	if !hasMatch {
+		_, hasRegex := matcher["regex"]
+		_, hasMin := matcher["min"]
+		_, hasMax := matcher["max"]
+		if !hasRegex && !hasMin && !hasMax {
+			l.report(SeverityError, RuleUnknownMatcher, path, "matcher has no match type")
+		}
+		return
+	}
+	matchType, ok := match.(string)
+	if !ok || !knownMatcherTypes[matchType] {
+		l.report(SeverityError, RuleUnknownMatcher, path.key("match"), "unknown matcher type %v", match)
+		return
+	}
+	if matchType == "regex" {
+		pattern, ok := matcher["regex"].(string)
+		if !ok {
+			l.report(SeverityError, RuleSchema, path.key("regex"), "regex matcher needs a regex")
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			l.report(SeverityWarning, RuleSchema, path.key("regex"), "regex %q cannot be checked: %s", pattern, err)
+		}
+	}
+}
+
+// checkMatcherTarget reports v3 matching rules whose target does not exist in the example.
+func (l *pactLinter) checkMatcherTarget(owner map[string]interface{}, category, key string, path jsonPath) {
+	switch category {
+	case "body":
+		segments, err := parseMatcherPath(key)
+		if err != nil {
+			l.report(SeverityError, RuleDanglingMatcher, path, "%s", err)
+			return
+		}
+		l.checkBodyPath(owner, segments, key, path)
+	case "header", "headers":
+		headers, _ := owner["headers"].(map[string]interface{})
+		for name := range headers {
+			if strings.EqualFold(name, key) {
+				return
+			}
+		}
+		l.report(SeverityError, RuleDanglingMatcher, path, "matching rule targets header %q which is not in the example", key)
+	case "query":
+		if query, ok := owner["query"].(map[string]interface{}); ok {
+			if _, ok := query[key]; ok {
+				return
+			}
+		} else if query, ok := owner["query"].(string); ok && strings.Contains("&"+query, "&"+key+"=") {
+			return
+		}
+		l.report(SeverityError, RuleDanglingMatcher, path, "matching rule targets query parameter %q which is not in the example", key)
+	}
+}
+
+func (l *pactLinter) checkBodyPath(owner map[string]interface{}, segments []matcherPathSegment, expr string, path jsonPath) {
+	body, ok := owner["body"]
+	if !ok {
+		body, ok = owner["contents"]
+	}
+	if !ok {
+		l.report(SeverityError, RuleDanglingMatcher, path, "matching rule %q targets a body but the example has none", expr)
+		return
+	}
+	nodes, emptyWildcard := selectMatcherPath(body, segments)
+	if len(nodes) == 0 && !emptyWildcard {
+		l.report(SeverityError, RuleDanglingMatcher, path, "matching rule %q does not match anything in the example body", expr)
+	}
+}
@@ -0,0 +1,88 @@
+package pacttesting
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pactLintStage struct {
+	t           *testing.T
+	files       []string
+	content     []byte
+	diagnostics []Diagnostic
+}
+
+func PactLintTest(t *testing.T) (*pactLintStage, *pactLintStage, *pactLintStage) {
+	t.Helper()
+	s := &pactLintStage{t: t}
+	return s, s, s
+}
+
+func (s *pactLintStage) and() *pactLintStage {
+	return s
+}
+
+func (s *pactLintStage) a_pact_file(path string) *pactLintStage {
+	s.files = append(s.files, path)
+	return s
+}
+
+func (s *pactLintStage) all_pact_files_in(dir string) *pactLintStage {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(s.t, err)
+	require.NotEmpty(s.t, files)
+	s.files = append(s.files, files...)
+	return s
+}
+
+func (s *pactLintStage) pact_content(content string) *pactLintStage {
+	s.content = []byte(content)
+	return s
+}
+
+func (s *pactLintStage) the_pact_file_is_linted() *pactLintStage {
+	if s.content != nil {
+		s.diagnostics = Lint("inline.json", s.content)
+		return s
+	}
+	diagnostics, err := LintPactFiles(s.files...)
+	require.NoError(s.t, err)
+	s.diagnostics = diagnostics
+	return s
+}
+
+func (s *pactLintStage) no_diagnostics_are_reported() *pactLintStage {
+	assert.Empty(s.t, s.diagnostics)
+	return s
+}
+
+func (s *pactLintStage) no_errors_are_reported() *pactLintStage {
+	assert.False(s.t, HasErrors(s.diagnostics), "unexpected errors: %v", s.diagnostics)
+	return s
+}
+
+func (s *pactLintStage) a_diagnostic_is_reported(rule string, severity Severity, line, column int) *pactLintStage {
+	for _, d := range s.diagnostics {
+		if d.Rule == rule && d.Severity == severity && d.Line == line && d.Column == column {
+			return s
+		}
+	}
+	s.t.Errorf("expected %s %s at %d:%d, got %v", severity, rule, line, column, s.diagnostics)
+	return s
+}
+
+func (s *pactLintStage) validation_succeeds() *pactLintStage {
+	assert.NoError(s.t, Validate(s.files...))
+	return s
+}
+
+func (s *pactLintStage) validation_fails() *pactLintStage {
+	err := Validate(s.files...)
+	var validationErr *ValidationError
+	require.ErrorAs(s.t, err, &validationErr)
+	assert.True(s.t, HasErrors(validationErr.Diagnostics))
+	return s
+}
@@ -0,0 +1,98 @@
+package pacttesting
+
+import "testing"
+
+func TestLint_ValidPactHasNoDiagnostics(t *testing.T) {
+	given, when, then := PactLintTest(t)
+
+	given.
+		a_pact_file("lintpacts/valid.json")
+
+	when.
+		the_pact_file_is_linted()
+
+	then.
+		no_diagnostics_are_reported().and().
+		validation_succeeds()
+}
+
+func TestLint_ExistingFixturesHaveNoErrors(t *testing.T) {
+	given, when, then := PactLintTest(t)
+
+	given.
+		all_pact_files_in("pacts").and().
+		all_pact_files_in("messagepacts")
+
+	when.
+		the_pact_file_is_linted()
+
+	then.
+		no_errors_are_reported()
+}
+
+func TestLint_BrokenPactReportsPositionedDiagnostics(t *testing.T) {
+	given, when, then := PactLintTest(t)
+
+	given.
+		a_pact_file("lintpacts/broken.json")
+
+	when.
+		the_pact_file_is_linted()
+
+	then.
+		a_diagnostic_is_reported(RuleMissingProvider, SeverityError, 2, 25).and().
+		a_diagnostic_is_reported(RuleDanglingMatcher, SeverityError, 16, 29).and().
+		a_diagnostic_is_reported(RuleUnknownMatcher, SeverityError, 17, 32).and().
+		a_diagnostic_is_reported(RuleStateCollision, SeverityError, 22, 22).and().
+		a_diagnostic_is_reported(RuleDuplicateDescription, SeverityWarning, 32, 22).and().
+		a_diagnostic_is_reported(RuleSpecMetadata, SeverityError, 45, 40).and().
+		validation_fails()
+}
+
+func TestLint_InvalidJSONReportsSyntaxError(t *testing.T) {
+	given, when, then := PactLintTest(t)
+
+	given.
+		pact_content("{\n  \"provider\": {\n}")
+
+	when.
+		the_pact_file_is_linted()
+
+	then.
+		a_diagnostic_is_reported(RuleSyntax, SeverityError, 3, 2)
+}
+
+func TestLint_InvalidMatcherShorthandIsReported(t *testing.T) {
+	given, when, then := PactLintTest(t)
+
+	given.
+		pact_content(`{
+  "consumer": {"name": "consumer"},
+  "provider": {"name": "provider"},
+  "interactions": [{
+    "description": "a request",
+    "request": {"method": "GET", "path": "/"},
+    "response": {"status": 200, "body": {"id": {"$regex": "^\\d+$"}}}
+  }]
+}`)
+
+	when.
+		the_pact_file_is_linted()
+
+	then.
+		a_diagnostic_is_reported(RuleMatcherShorthand, SeverityError, 7, 48)
+}
+
+func TestLint_ReferenceCycleIsReported(t *testing.T) {
+	given, when, then := PactLintTest(t)
+
+	given.
+		a_pact_file("lintpacts/refcycle/a.json")
+
+	when.
+		the_pact_file_is_linted()
+
+	then.
+		a_diagnostic_is_reported(RuleFixtureRef, SeverityError, 8, 19).and().
+		validation_fails()
+}
@@ -0,0 +1,274 @@
+package pacttesting
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PactLoader loads pact fixtures for consumer tests. Its methods mirror the package level
+// AddPact, PreassignPorts, TestWithStubServices, IntegrationTest and RunIntegrationTest, which use a
+// zero PactLoader, i.e. files are read from <cwd>/pacts as they are.
+//
+// Pact names are resolved like the package level functions do: the suffix is optional and
+// <name>.json is preferred over <name>.yaml and <name>.yml. YAML fixtures have the same structure as
+// JSON pacts and are converted to JSON when they are loaded. Matcher shorthand ($like, $regex and
+// $eachLike) is expanded into examples and matching rules, see expandMatcherShorthand. Objects holding a
+// $ref are replaced by shared fragments, see fixtureRefKey.
+type PactLoader struct {
+	// FS is the file system pact files are read from, e.g. an embed.FS shipped by a test-helper module.
+	// If nil, pact files are read from disk.
+	FS fs.FS
+	// Dir is the directory pact files are resolved in. Within FS it is a slash separated path and
+	// defaults to the root of FS. On disk it is absolute or relative to the working directory and
+	// defaults to <cwd>/pacts.
+	Dir string
+	// Variables are substituted into ${name} placeholders in the string values and keys of pact files.
+	// ${name:-default} falls back to default when name is not set and $${name} is left as a literal ${name}.
+	// Placeholders are resolved before interactions reach the mock service, so pacts written to target/
+	// never contain them.
+	Variables map[string]string
+	// UseEnv resolves placeholders that are not in Variables from environment variables.
+	UseEnv bool
+	// Selectors, if any, restrict every loaded pact file to the interactions that at least one of them selects.
+	// Use AllOf to require several conditions at once.
+	Selectors []InteractionSelector
+}
+
+// UnresolvedVariablesError is returned when a pact file contains placeholders that neither
+// PactLoader.Variables nor (if enabled) the environment provide a value for.
+type UnresolvedVariablesError struct {
+	File  string
+	Names []string
+}
+
+func (e *UnresolvedVariablesError) Error() string {
+	return fmt.Sprintf("unresolved variables in pact file '%s': %s", e.File, strings.Join(e.Names, ", "))
+}
+
+//nolint:gochecknoglobals // compiled once
+var placeholderPattern = regexp.MustCompile(`\$(\$?)\{([^{}]*)\}`)
+
+func (l *PactLoader) readPactFile(pactFilePath string) (*pact, error) {
+	doc, err := l.loadPactDocument(pactFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return pactFromDocument(doc), nil
+}
+
+// ResolvePact returns a pact fixture as the loader sends it to the mock service: converted from YAML,
+// with fragment references, variables and matcher shorthand resolved. It is meant for debugging fixtures.
+func (l *PactLoader) ResolvePact(name string) ([]byte, error) {
+	doc, err := l.loadPactDocument(name)
+	if err != nil {
+		return nil, err
+	}
+	return marshalPactDocument(doc)
+}
+
+func (l *PactLoader) loadPactDocument(pactFilePath string) (pactDocument, error) {
+	resolved, location, err := l.resolvePactDocument(pactFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	trackPactLoaded(location, resolved)
+	if err := l.selectInteractions(resolved, location); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// resolvePactDocument reads a pact fixture and returns it as it is sent to the mock service, before selectors are
+// applied, together with its location for error messages.
+func (l *PactLoader) resolvePactDocument(pactFilePath string) (pactDocument, string, error) {
+	pactString, file, location, err := l.readPactFixture(pactFilePath)
+	if err != nil {
+		return nil, location, fmt.Errorf("reading pact file: %w", err)
+	}
+
+	if isYAMLFile(file) {
+		if pactString, err = ConvertYAMLToJSON(pactString); err != nil {
+			return nil, location, fmt.Errorf("converting pact file '%s': %w", location, err)
+		}
+	}
+
+	doc, err := parsePactDocument(pactString)
+	if err != nil {
+		return nil, location, fmt.Errorf("parsing pact file '%s': %w", location, err)
+	}
+
+	composed, err := l.resolveFixtureRefs(file, doc)
+	if err != nil {
+		return nil, location, fmt.Errorf("resolving references in pact file '%s': %w", location, err)
+	}
+
+	unresolved := make(map[string]bool)
+	resolved, _ := l.substituteVariables(composed, unresolved).(map[string]interface{})
+	if len(unresolved) > 0 {
+		names := make([]string, 0, len(unresolved))
+		for name := range unresolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, location, &UnresolvedVariablesError{File: location, Names: names}
+	}
+
+	if err := expandMatcherShorthand(resolved); err != nil {
+		return nil, location, fmt.Errorf("expanding matchers in pact file '%s': %w", location, err)
+	}
+
+	return resolved, location, nil
+}
+
+// readPactFixture reads the fixture for a pact name, returning its content, the file name relative to the
+// loader directory and a location for error messages. A name ending in .json, .yaml or .yml, in any case, is read
+// as it is, otherwise <name>.json, <name>.yaml and <name>.yml are tried in that order.
+func (l *PactLoader) readPactFixture(name string) ([]byte, string, string, error) {
+	candidates := []string{name}
+	if !isJSONFile(name) && !isYAMLFile(name) {
+		candidates = []string{name + ".json", name + ".yaml", name + ".yml"}
+	}
+	var firstErr error
+	for _, file := range candidates {
+		data, location, err := l.readFixture(file)
+		if err == nil {
+			return data, file, location, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, file, location, err
+		}
+	}
+	return nil, "", "", firstErr
+}
+
+// isJSONFile reports whether name has a .json extension, in any case.
+func isJSONFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".json")
+}
+
+// readFixture reads a fixture file from FS or disk, returning its content and a location for error messages.
+func (l *PactLoader) readFixture(file string) ([]byte, string, error) {
+	if l.FS != nil {
+		dir := l.Dir
+		if dir == "" {
+			dir = "."
+		}
+		name := path.Join(dir, file)
+		data, err := fs.ReadFile(l.FS, name)
+		if err != nil {
+			return nil, name, fmt.Errorf("reading %s from file system: %w", name, err)
+		}
+		return data, name, nil
+	}
+
+	dir := l.Dir
+	if dir == "" {
+		cwd, _ := os.Getwd()
+		dir = filepath.Join(cwd, "pacts")
+	}
+	name := filepath.FromSlash(filepath.Join(dir, file))
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, name, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return data, name, nil
+}
+
+func (l *PactLoader) readAllPacts(pacts []string) ([]*pact, error) {
+	results := make([]*pact, len(pacts))
+	for i, p := range pacts {
+		loaded, err := l.readPactFile(p)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = loaded
+	}
+
+	return results, nil
+}
+
+// mustReadAllPacts reads pacts for the test helpers that have always panicked on unreadable pact files.
+func (l *PactLoader) mustReadAllPacts(pacts []string) []*pact {
+	results, err := l.readAllPacts(pacts)
+	if err != nil {
+		panic(err)
+	}
+	return results
+}
+
+func pactFromDocument(doc pactDocument) *pact {
+	p := &pact{
+		Consumer:     pactName{Name: participantName(doc, "consumer")},
+		Provider:     pactName{Name: participantName(doc, "provider")},
+		Interactions: []interface{}{},
+	}
+	if interactions, ok := doc["interactions"].([]interface{}); ok {
+		p.Interactions = interactions
+	}
+	return p
+}
+
+func (l *PactLoader) lookupVariable(name string) (string, bool) {
+	if value, ok := l.Variables[name]; ok {
+		return value, true
+	}
+	if l.UseEnv {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+// substituteVariables replaces placeholders in all strings and object keys of v, recording
+// the names it could not resolve.
+func (l *PactLoader) substituteVariables(v interface{}, unresolved map[string]bool) interface{} {
+	switch value := v.(type) {
+	case string:
+		return l.substituteString(value, unresolved)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, child := range value {
+			result[l.substituteString(k, unresolved)] = l.substituteVariables(child, unresolved)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, child := range value {
+			result[i] = l.substituteVariables(child, unresolved)
+		}
+		return result
+	}
+	return v
+}
+
+func (l *PactLoader) substituteString(s string, unresolved map[string]bool) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := placeholderPattern.FindStringSubmatch(match)
+		if groups[1] == "$" {
+			return match[1:]
+		}
+		name, fallback, hasFallback := strings.Cut(groups[2], ":-")
+		if value, ok := l.lookupVariable(name); ok {
+			return value
+		}
+		if hasFallback {
+			return fallback
+		}
+		unresolved[name] = true
+		return match
+	})
+}
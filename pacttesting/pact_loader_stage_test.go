@@ -0,0 +1,189 @@
+package pacttesting
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals // embedded fixtures
+//go:embed pacts/*.json
+var embeddedPacts embed.FS
+
+type pactLoaderStage struct {
+	t        *testing.T
+	loader   *PactLoader
+	pact     *pact
+	err      error
+	response *http.Response
+	resolved []byte
+}
+
+func PactLoaderTest(t *testing.T) (*pactLoaderStage, *pactLoaderStage, *pactLoaderStage) {
+	t.Helper()
+	s := &pactLoaderStage{t: t, loader: &PactLoader{}}
+	return s, s, s
+}
+
+func (s *pactLoaderStage) and() *pactLoaderStage {
+	return s
+}
+
+func (s *pactLoaderStage) a_loader_with_variables(variables map[string]string) *pactLoaderStage {
+	s.loader.Variables = variables
+	return s
+}
+
+func (s *pactLoaderStage) a_loader_using_the_environment() *pactLoaderStage {
+	s.loader.UseEnv = true
+	return s
+}
+
+func (s *pactLoaderStage) the_environment_variable(name, value string) *pactLoaderStage {
+	s.t.Setenv(name, value)
+	return s
+}
+
+func (s *pactLoaderStage) the_pact_is_loaded(name string) *pactLoaderStage {
+	s.pact, s.err = s.loader.readPactFile(name)
+	return s
+}
+
+func (s *pactLoaderStage) no_error_is_returned() *pactLoaderStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *pactLoaderStage) interaction(index int) map[string]interface{} {
+	require.Greater(s.t, len(s.pact.Interactions), index)
+	interaction, ok := s.pact.Interactions[index].(map[string]interface{})
+	require.True(s.t, ok)
+	return interaction
+}
+
+func (s *pactLoaderStage) the_interaction_has_path(path string) *pactLoaderStage {
+	request, _ := s.interaction(0)["request"].(map[string]interface{})
+	assert.Equal(s.t, path, request["path"])
+	return s
+}
+
+func (s *pactLoaderStage) the_response_body_has(field, value string) *pactLoaderStage {
+	response, _ := s.interaction(0)["response"].(map[string]interface{})
+	body, _ := response["body"].(map[string]interface{})
+	assert.Equal(s.t, value, body[field])
+	return s
+}
+
+func (s *pactLoaderStage) variables_are_unresolved(names ...string) *pactLoaderStage {
+	var unresolvedErr *UnresolvedVariablesError
+	require.ErrorAs(s.t, s.err, &unresolvedErr)
+	assert.Equal(s.t, names, unresolvedErr.Names)
+	return s
+}
+
+func (s *pactLoaderStage) the_organisation_is_fetched_from_service_a(id string) *pactLoaderStage {
+	url := viper.GetString("testservicea") + "/v1/organisations/" + id
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, url, nil)
+	require.NoError(s.t, err)
+
+	s.response, s.err = http.DefaultClient.Do(req)
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *pactLoaderStage) the_organisation_response_is_200_ok() *pactLoaderStage {
+	assert.Equal(s.t, http.StatusOK, s.response.StatusCode)
+	return s
+}
+
+func (s *pactLoaderStage) a_loader_reading_from_the_embedded_fixtures() *pactLoaderStage {
+	s.loader.FS = embeddedPacts
+	s.loader.Dir = "pacts"
+	return s
+}
+
+func (s *pactLoaderStage) a_loader_reading_from_directory(dir string) *pactLoaderStage {
+	s.loader.Dir = dir
+	return s
+}
+
+func (s *pactLoaderStage) a_loader_reading_a_copy_of(source, name string) *pactLoaderStage {
+	data, err := os.ReadFile(source)
+	require.NoError(s.t, err)
+	s.loader.Dir = s.t.TempDir()
+	require.NoError(s.t, os.WriteFile(filepath.Join(s.loader.Dir, name), data, 0o600))
+	return s
+}
+
+func (s *pactLoaderStage) a_missing_file_error_is_returned() *pactLoaderStage {
+	assert.ErrorIs(s.t, s.err, fs.ErrNotExist)
+	return s
+}
+
+func (s *pactLoaderStage) the_pact_is_resolved(name string) *pactLoaderStage {
+	s.resolved, s.err = s.loader.ResolvePact(name)
+	return s
+}
+
+func (s *pactLoaderStage) the_interaction_has_provider_state(index int, name string) *pactLoaderStage {
+	assert.Equal(s.t, []string{name}, providerStateNames(s.interaction(index)))
+	return s
+}
+
+func (s *pactLoaderStage) the_response_has_status(index int, status int64) *pactLoaderStage {
+	response, _ := s.interaction(index)["response"].(map[string]interface{})
+	actual, _ := response["status"].(json.Number)
+	n, err := actual.Int64()
+	require.NoError(s.t, err)
+	assert.Equal(s.t, status, n)
+	return s
+}
+
+func (s *pactLoaderStage) the_response_has_header(index int, name, value string) *pactLoaderStage {
+	response, _ := s.interaction(index)["response"].(map[string]interface{})
+	headers, _ := response["headers"].(map[string]interface{})
+	assert.Equal(s.t, value, headers[name])
+	return s
+}
+
+func (s *pactLoaderStage) a_reference_cycle_is_reported(chain ...string) *pactLoaderStage {
+	var cycleErr *FixtureRefCycleError
+	require.ErrorAs(s.t, s.err, &cycleErr)
+	assert.Equal(s.t, chain, cycleErr.Chain)
+	return s
+}
+
+func (s *pactLoaderStage) the_resolved_pact_does_not_contain(text string) *pactLoaderStage {
+	assert.NotContains(s.t, string(s.resolved), text)
+	return s
+}
+
+func (s *pactLoaderStage) a_loader_selecting(selectors ...InteractionSelector) *pactLoaderStage {
+	s.loader.Selectors = selectors
+	return s
+}
+
+func (s *pactLoaderStage) the_pact_has_descriptions(descriptions ...string) *pactLoaderStage {
+	actual := make([]string, 0, len(s.pact.Interactions))
+	for i := range s.pact.Interactions {
+		description, _ := s.interaction(i)["description"].(string)
+		actual = append(actual, description)
+	}
+	assert.Equal(s.t, descriptions, actual)
+	return s
+}
+
+func (s *pactLoaderStage) an_error_is_returned_containing(text string) *pactLoaderStage {
+	require.Error(s.t, s.err)
+	assert.Contains(s.t, s.err.Error(), text)
+	return s
+}
@@ -0,0 +1,150 @@
+package pacttesting
+
+import "testing"
+
+func TestLoader_VariablesAreSubstituted(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_with_variables(map[string]string{"ORGANISATION_ID": "743d5b63"})
+
+	when.
+		the_pact_is_loaded("testservicea.get.variables.test")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_path("/v1/organisations/743d5b63").and().
+		the_response_body_has("id", "743d5b63").and().
+		the_response_body_has("host", "localhost").and().
+		the_response_body_has("template", "${NOT_A_VARIABLE}")
+}
+
+func TestLoader_VariablesAreResolvedFromEnvironment(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_using_the_environment().and().
+		the_environment_variable("ORGANISATION_ID", "6e9224ee").and().
+		the_environment_variable("HOST", "example.com")
+
+	when.
+		the_pact_is_loaded("testservicea.get.variables.test")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_path("/v1/organisations/6e9224ee").and().
+		the_response_body_has("host", "example.com")
+}
+
+func TestLoader_UnresolvedVariablesAreAnError(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_with_variables(map[string]string{})
+
+	when.
+		the_pact_is_loaded("testservicea.get.variables.test")
+
+	then.
+		variables_are_unresolved("ORGANISATION_ID")
+}
+
+func TestAcc_verify_pact_with_variables(t *testing.T) {
+	loader := &PactLoader{Variables: map[string]string{"ORGANISATION_ID": "743d5b63"}}
+	loader.IntegrationTest([]Pact{"testservicea.get.variables.test"}, func() {
+		given, when, then := PactLoaderTest(t)
+
+		given.
+			a_loader_with_variables(loader.Variables)
+
+		when.
+			the_organisation_is_fetched_from_service_a("743d5b63")
+
+		then.
+			the_organisation_response_is_200_ok()
+	})
+}
+
+func TestLoader_PactsAreReadFromEmbeddedFS(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_the_embedded_fixtures()
+
+	when.
+		the_pact_is_loaded("testservicea.get.test")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_path("/v1/test")
+}
+
+func TestLoader_PactsAreReadFromDirectory(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("mergepacts")
+
+	when.
+		the_pact_is_loaded("testservicea.first.json")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_path("/v1/test")
+}
+
+func TestLoader_MissingPactIsAnError(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_the_embedded_fixtures()
+
+	when.
+		the_pact_is_loaded("testservicec.get.test")
+
+	then.
+		a_missing_file_error_is_returned()
+}
+
+func TestLoader_YAMLPactsAreConvertedToJSON(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_from_directory("pacts")
+
+	when.
+		the_pact_is_loaded("testservicea.get.yaml.test")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_path("/v1/test").and().
+		the_response_body_has("enabled", "true")
+}
+
+func TestLoader_YAMLExtensionsAreCaseInsensitive(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_a_copy_of("pacts/testservicea.get.yaml.test.yaml", "testservicea.get.upper.YML")
+
+	when.
+		the_pact_is_loaded("testservicea.get.upper.YML")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_path("/v1/test")
+}
+
+func TestLoader_JSONExtensionIsCaseInsensitive(t *testing.T) {
+	given, when, then := PactLoaderTest(t)
+
+	given.
+		a_loader_reading_a_copy_of("pacts/testservicea.get.test.json", "testservicea.get.upper.JSON")
+
+	when.
+		the_pact_is_loaded("testservicea.get.upper.JSON")
+
+	then.
+		no_error_is_returned().and().
+		the_interaction_has_path("/v1/test")
+}
@@ -0,0 +1,190 @@
+package pacttesting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MergedPact holds all interactions of one provider/consumer pair, merged from one or more pact files.
+type MergedPact struct {
+	Consumer string
+	Provider string
+	// Sources lists the files that contributed interactions, in the order they were merged.
+	Sources []string
+	// Duplicates counts identical interactions dropped while merging.
+	Duplicates int
+	document   pactDocument
+}
+
+// MergeConflict describes two interactions with the same description and provider state
+// whose requests, responses or message contents differ.
+type MergeConflict struct {
+	Consumer       string
+	Provider       string
+	Description    string
+	ProviderStates []string
+	Files          [2]string
+	// Fields lists the interaction fields that differ, e.g. request or response.
+	Fields []string
+}
+
+func (c MergeConflict) String() string {
+	state := ""
+	if len(c.ProviderStates) > 0 {
+		state = fmt.Sprintf(" given %q", strings.Join(c.ProviderStates, ", "))
+	}
+	return fmt.Sprintf("%s -> %s: interaction %q%s differs in %s between %s and %s",
+		c.Consumer, c.Provider, c.Description, state, strings.Join(c.Fields, ", "), c.Files[0], c.Files[1])
+}
+
+// MergeConflictError is returned by MergePactFiles when conflicting interactions were found.
+type MergeConflictError struct {
+	Conflicts []MergeConflict
+}
+
+func (e *MergeConflictError) Error() string {
+	lines := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		lines = append(lines, c.String())
+	}
+	return fmt.Sprintf("%d conflicting interactions:\n%s", len(e.Conflicts), strings.Join(lines, "\n"))
+}
+
+// Interactions returns the number of interactions (or messages) in the merged pact.
+func (m *MergedPact) Interactions() int {
+	count := 0
+	for _, field := range pactInteractionFields {
+		items, _ := m.document[field].([]interface{})
+		count += len(items)
+	}
+	return count
+}
+
+// FileName returns the name the pact mock service would give this pact, <consumer>-<provider>.json.
+func (m *MergedPact) FileName() string {
+	return pactFileName(m.Consumer, m.Provider)
+}
+
+// JSON returns the merged pact file content.
+func (m *MergedPact) JSON() ([]byte, error) {
+	return marshalPactDocument(m.document)
+}
+
+// MergePactFiles merges the given pact files per provider/consumer pair. Identical interactions
+// are kept once. Interactions sharing a description and provider state but differing otherwise are
+// reported through a *MergeConflictError; in that case the merged pacts keep the first occurrence
+// and are still returned so callers can decide whether to use them.
+func MergePactFiles(paths ...string) ([]*MergedPact, error) {
+	merged := make(map[string]*MergedPact)
+	var order []string
+	var conflicts []MergeConflict
+
+	type origin struct {
+		file        string
+		interaction map[string]interface{}
+	}
+	seen := make(map[string]origin)
+
+	for _, path := range paths {
+		doc, err := readPactDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		consumer, provider := participantName(doc, "consumer"), participantName(doc, "provider")
+		pairKey := provider + "\x00" + consumer
+		m, ok := merged[pairKey]
+		if !ok {
+			m = &MergedPact{Consumer: consumer, Provider: provider, document: pactDocument{
+				"consumer": doc["consumer"],
+				"provider": doc["provider"],
+			}}
+			merged[pairKey] = m
+			order = append(order, pairKey)
+		}
+		m.Sources = append(m.Sources, path)
+		if _, ok := m.document["metadata"]; !ok && doc["metadata"] != nil {
+			m.document["metadata"] = doc["metadata"]
+		}
+
+		for _, field := range pactInteractionFields {
+			if _, ok := doc[field]; !ok {
+				continue
+			}
+			items, _ := m.document[field].([]interface{})
+			if items == nil {
+				items = []interface{}{}
+			}
+			for _, interaction := range documentInteractions(doc, field) {
+				key := pairKey + "\x00" + field + "\x00" + interactionKey(interaction)
+				previous, ok := seen[key]
+				if !ok {
+					seen[key] = origin{file: path, interaction: interaction}
+					items = append(items, interaction)
+					continue
+				}
+				fields := differingFields(previous.interaction, interaction)
+				if len(fields) == 0 {
+					m.Duplicates++
+					continue
+				}
+				description, _ := interaction["description"].(string)
+				conflicts = append(conflicts, MergeConflict{
+					Consumer:       consumer,
+					Provider:       provider,
+					Description:    description,
+					ProviderStates: providerStateNames(interaction),
+					Files:          [2]string{previous.file, path},
+					Fields:         fields,
+				})
+			}
+			m.document[field] = items
+		}
+	}
+
+	results := make([]*MergedPact, 0, len(order))
+	for _, key := range order {
+		results = append(results, merged[key])
+	}
+	if len(conflicts) > 0 {
+		return results, &MergeConflictError{Conflicts: conflicts}
+	}
+	return results, nil
+}
+
+// WriteMergedPacts writes each merged pact to outputDirPath using MergedPact.FileName.
+func WriteMergedPacts(merged []*MergedPact, outputDirPath string) error {
+	if err := os.MkdirAll(outputDirPath, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't create output directory '%s': %w", outputDirPath, err)
+	}
+	for _, m := range merged {
+		content, err := m.JSON()
+		if err != nil {
+			return fmt.Errorf("merging pacts for %s and %s: %w", m.Consumer, m.Provider, err)
+		}
+		path := filepath.Join(outputDirPath, m.FileName())
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return fmt.Errorf("couldn't write merged pact file '%s': %w", path, err)
+		}
+	}
+	return nil
+}
+
+// mergeComparedFields are the interaction fields that must be equal for two interactions with the same
+// description and provider state to be duplicates. Other fields, e.g. a provider state written as v2
+// providerState in one file and as v3 providerStates in the other, do not make them conflict.
+//
+//nolint:gochecknoglobals // constant list
+var mergeComparedFields = []string{"contents", "metaData", "request", "response"}
+
+// differingFields returns the sorted names of the compared fields that differ between two interactions.
+func differingFields(a, b map[string]interface{}) []string {
+	var fields []string
+	for _, k := range mergeComparedFields {
+		if canonicalJSON(a[k]) != canonicalJSON(b[k]) {
+			fields = append(fields, k)
+		}
+	}
+	return fields
+}
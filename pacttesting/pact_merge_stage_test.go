@@ -0,0 +1,80 @@
+package pacttesting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pactMergeStage struct {
+	t         *testing.T
+	files     []string
+	outputDir string
+	merged    []*MergedPact
+	err       error
+}
+
+func PactMergeTest(t *testing.T) (*pactMergeStage, *pactMergeStage, *pactMergeStage) {
+	t.Helper()
+	s := &pactMergeStage{t: t, outputDir: t.TempDir()}
+	return s, s, s
+}
+
+func (s *pactMergeStage) and() *pactMergeStage {
+	return s
+}
+
+func (s *pactMergeStage) pact_files(files ...string) *pactMergeStage {
+	s.files = files
+	return s
+}
+
+func (s *pactMergeStage) the_pact_files_are_merged() *pactMergeStage {
+	s.merged, s.err = MergePactFiles(s.files...)
+	return s
+}
+
+func (s *pactMergeStage) no_conflicts_are_reported() *pactMergeStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *pactMergeStage) a_conflict_is_reported(description, state, field string) *pactMergeStage {
+	var conflictErr *MergeConflictError
+	require.ErrorAs(s.t, s.err, &conflictErr)
+	require.Len(s.t, conflictErr.Conflicts, 1)
+	conflict := conflictErr.Conflicts[0]
+	assert.Equal(s.t, description, conflict.Description)
+	assert.Equal(s.t, []string{state}, conflict.ProviderStates)
+	assert.Equal(s.t, []string{field}, conflict.Fields)
+	assert.Equal(s.t, [2]string{s.files[0], s.files[1]}, conflict.Files)
+	return s
+}
+
+func (s *pactMergeStage) merged_pacts_are_written() *pactMergeStage {
+	require.NoError(s.t, WriteMergedPacts(s.merged, s.outputDir))
+	return s
+}
+
+func (s *pactMergeStage) the_merged_pact_has_interactions(fileName string, count int) *pactMergeStage {
+	data, err := os.ReadFile(filepath.Join(s.outputDir, fileName))
+	require.NoError(s.t, err)
+	pactFile, err := NewPactFile(data)
+	require.NoError(s.t, err)
+	assert.Len(s.t, pactFile.Interactions, count)
+	return s
+}
+
+func (s *pactMergeStage) duplicates_were_dropped(provider string, count int) *pactMergeStage {
+	for _, m := range s.merged {
+		if m.Provider == provider {
+			assert.Equal(s.t, count, m.Duplicates)
+			return s
+		}
+	}
+	s.t.Errorf("no merged pact for provider %s", provider)
+	return s
+}
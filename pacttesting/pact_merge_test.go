@@ -0,0 +1,52 @@
+package pacttesting
+
+import "testing"
+
+func TestMerge_PactsArePerProviderAndConsumer(t *testing.T) {
+	given, when, then := PactMergeTest(t)
+
+	given.
+		pact_files("pacts/testservicea.get.test.json", "pacts/testserviceb.get.test.json", "pacts/testservices.get.bulk.test.json")
+
+	when.
+		the_pact_files_are_merged()
+
+	then.
+		no_conflicts_are_reported().and().
+		merged_pacts_are_written().and().
+		the_merged_pact_has_interactions("go-pact-testing-testservicea.json", 2).and().
+		the_merged_pact_has_interactions("go-pact-testing-testserviceb.json", 1).and().
+		duplicates_were_dropped("testservicea", 1)
+}
+
+func TestMerge_ConflictingInteractionsAreReported(t *testing.T) {
+	given, when, then := PactMergeTest(t)
+
+	given.
+		pact_files("mergepacts/testservicea.first.json", "mergepacts/testservicea.second.json")
+
+	when.
+		the_pact_files_are_merged()
+
+	then.
+		a_conflict_is_reported("Request for a test endpoint B", "endpoint B exists", "response").and().
+		merged_pacts_are_written().and().
+		the_merged_pact_has_interactions("go-pact-testing-testservicea.json", 3).and().
+		duplicates_were_dropped("testservicea", 1)
+}
+
+func TestMerge_OnlyRequestsResponsesAndContentsCanConflict(t *testing.T) {
+	given, when, then := PactMergeTest(t)
+
+	given.
+		pact_files("mergepacts/testservicea.second.json", "mergepacts/testservicea.v3.json")
+
+	when.
+		the_pact_files_are_merged()
+
+	then.
+		no_conflicts_are_reported().and().
+		merged_pacts_are_written().and().
+		the_merged_pact_has_interactions("go-pact-testing-testservicea.json", 3).and().
+		duplicates_were_dropped("testservicea", 2)
+}
@@ -239,6 +239,11 @@ func (s *pactTestingStage) test_service_a_returns_200_for_get_from_file() *pactT
 	return s
 }
 
+func (s *pactTestingStage) test_service_a_returns_200_for_get_selected_from_shared_file() *pactTestingStage {
+	assert.NoError(s.t, AddPactSelecting("testservices.get.bulk.test", WithDescription("Request for a test endpoint A")))
+	return s
+}
+
 func (s *pactTestingStage) test_service_a_is_called() *pactTestingStage {
 	s.testServiceApid = pactServers["testserviceago-pact-testing"].Pid
 	return s.the_pact_for_service_a_is_called()
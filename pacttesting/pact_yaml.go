@@ -0,0 +1,229 @@
+package pacttesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConvertYAMLToJSON converts a pact fixture written in YAML into a canonical JSON pact.
+// The YAML document has the same structure as the JSON pact; comments, anchors, aliases and << merge keys are
+// allowed.
+func ConvertYAMLToJSON(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing yaml: %w", err)
+	}
+	if root.Kind == 0 {
+		return nil, errors.New("parsing yaml: empty document")
+	}
+	value, err := yamlNodeValue(&root)
+	if err != nil {
+		return nil, err
+	}
+	return marshalPactDocument(value)
+}
+
+// ConvertJSONToYAML converts a JSON pact into YAML, keeping the order of object keys.
+func ConvertJSONToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := jsonTokensToYAMLNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("parsing json: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing json: unexpected data after the top level value")
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("encoding yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encoding yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func isYAMLFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// yamlNodeValue converts a YAML node into the generic values produced by decodeJSON.
+func yamlNodeValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlNodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.MappingNode:
+		result := make(map[string]interface{}, len(node.Content)/2)
+		merged := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: only scalar keys are supported", key.Line)
+			}
+			if key.ShortTag() == "!!merge" {
+				if err := mergeYAMLMappings(merged, node.Content[i+1]); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			result[key.Value] = value
+		}
+		// keys of the mapping itself take precedence over merged keys
+		for k, v := range merged {
+			if _, ok := result[k]; !ok {
+				result[k] = v
+			}
+		}
+		return result, nil
+	case yaml.SequenceNode:
+		result := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			value, err := yamlNodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value
+		}
+		return result, nil
+	case yaml.ScalarNode:
+		return yamlScalarValue(node)
+	}
+	return nil, fmt.Errorf("line %d: unsupported yaml node", node.Line)
+}
+
+// mergeYAMLMappings adds the keys of the mapping, or sequence of mappings, that a << merge key refers to. Keys
+// already merged are kept, so that earlier mappings in a sequence take precedence.
+func mergeYAMLMappings(merged map[string]interface{}, node *yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.SequenceNode {
+		for _, child := range node.Content {
+			if err := mergeYAMLMappings(merged, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	value, err := yamlNodeValue(node)
+	if err != nil {
+		return err
+	}
+	mapping, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("line %d: << must merge a mapping or a sequence of mappings", node.Line)
+	}
+	for k, v := range mapping {
+		if _, ok := merged[k]; !ok {
+			merged[k] = v
+		}
+	}
+	return nil
+}
+
+func yamlScalarValue(node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		return b, nil
+	case "!!int":
+		var i int64
+		if err := node.Decode(&i); err != nil {
+			// too large for int64; keep the digits as they are
+			return json.Number(node.Value), nil //nolint:nilerr // not an error for json
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("line %d: %s cannot be represented in json", node.Line, node.Value)
+		}
+		if _, err := strconv.ParseFloat(node.Value, 64); err == nil && json.Valid([]byte(node.Value)) {
+			return json.Number(node.Value), nil
+		}
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	default:
+		return node.Value, nil
+	}
+}
+
+// jsonTokensToYAMLNode builds a YAML node from the next JSON value in dec, keeping key order.
+func jsonTokensToYAMLNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("reading json token: %w", err)
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if v == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, fmt.Errorf("reading json key: %w", err)
+				}
+				key, _ := keyTok.(string)
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key})
+			}
+			child, err := jsonTokensToYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("reading json delimiter: %w", err)
+		}
+		return node, nil
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(v, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
@@ -0,0 +1,77 @@
+package pacttesting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pactYAMLStage struct {
+	t        *testing.T
+	yamlPact []byte
+	jsonPact []byte
+	err      error
+}
+
+func PactYAMLTest(t *testing.T) (*pactYAMLStage, *pactYAMLStage, *pactYAMLStage) {
+	t.Helper()
+	s := &pactYAMLStage{t: t}
+	return s, s, s
+}
+
+func (s *pactYAMLStage) and() *pactYAMLStage {
+	return s
+}
+
+func (s *pactYAMLStage) a_yaml_pact(content string) *pactYAMLStage {
+	s.yamlPact = []byte(content)
+	return s
+}
+
+func (s *pactYAMLStage) a_json_pact(content string) *pactYAMLStage {
+	s.jsonPact = []byte(content)
+	return s
+}
+
+func (s *pactYAMLStage) the_yaml_is_converted_to_json() *pactYAMLStage {
+	if s.err == nil {
+		s.jsonPact, s.err = ConvertYAMLToJSON(s.yamlPact)
+	}
+	return s
+}
+
+func (s *pactYAMLStage) the_json_is_converted_to_yaml() *pactYAMLStage {
+	s.yamlPact, s.err = ConvertJSONToYAML(s.jsonPact)
+	return s
+}
+
+func (s *pactYAMLStage) no_conversion_error_is_returned() *pactYAMLStage {
+	require.NoError(s.t, s.err)
+	return s
+}
+
+func (s *pactYAMLStage) a_conversion_error_is_returned() *pactYAMLStage {
+	assert.Error(s.t, s.err)
+	return s
+}
+
+func (s *pactYAMLStage) the_json_is(expected string) *pactYAMLStage {
+	assert.JSONEq(s.t, expected, string(s.jsonPact))
+	return s
+}
+
+func (s *pactYAMLStage) the_yaml_keeps_key_order(keys ...string) *pactYAMLStage {
+	last := -1
+	for _, key := range keys {
+		pos := strings.Index(string(s.yamlPact), "\n"+key+":")
+		if strings.HasPrefix(string(s.yamlPact), key+":") {
+			pos = 0
+		}
+		require.GreaterOrEqual(s.t, pos, 0, "key %s not found in\n%s", key, s.yamlPact)
+		assert.Greater(s.t, pos, last, "key %s out of order in\n%s", key, s.yamlPact)
+		last = pos
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package pacttesting
+
+import "testing"
+
+func TestYAML_ConvertsToJSON(t *testing.T) {
+	given, when, then := PactYAMLTest(t)
+
+	given.
+		a_yaml_pact(`
+# comments are allowed
+consumer: {name: consumer}
+provider: {name: provider}
+interactions:
+  - description: &desc get organisation
+    request: {method: GET, path: /v1/organisations}
+    response:
+      status: 200
+      body: {id: 12345678901234567890, ratio: 1.50, flag: true, tag: "true", missing: null, again: *desc}
+`)
+
+	when.
+		the_yaml_is_converted_to_json()
+
+	then.
+		no_conversion_error_is_returned().and().
+		the_json_is(`{
+  "consumer": {"name": "consumer"},
+  "provider": {"name": "provider"},
+  "interactions": [{
+    "description": "get organisation",
+    "request": {"method": "GET", "path": "/v1/organisations"},
+    "response": {
+      "status": 200,
+      "body": {"id": 12345678901234567890, "ratio": 1.50, "flag": true, "tag": "true", "missing": null, "again": "get organisation"}
+    }
+  }]
+}`)
+}
+
+func TestYAML_MergeKeysAreExpanded(t *testing.T) {
+	given, when, then := PactYAMLTest(t)
+
+	given.
+		a_yaml_pact(`
+consumer: {name: consumer}
+provider: {name: provider}
+x-headers: &json {Content-Type: application/json, Accept: application/json}
+x-trace: &trace {X-Request-Id: "1", Accept: "*/*"}
+interactions:
+  - description: get organisation
+    request:
+      method: GET
+      path: /v1/organisations
+      headers:
+        <<: [*json, *trace]
+        X-Request-Id: "2"
+    response:
+      status: 200
+      headers: {<<: *json}
+`)
+
+	when.
+		the_yaml_is_converted_to_json()
+
+	then.
+		no_conversion_error_is_returned().and().
+		the_json_is(`{
+  "consumer": {"name": "consumer"},
+  "provider": {"name": "provider"},
+  "x-headers": {"Content-Type": "application/json", "Accept": "application/json"},
+  "x-trace": {"X-Request-Id": "1", "Accept": "*/*"},
+  "interactions": [{
+    "description": "get organisation",
+    "request": {"method": "GET", "path": "/v1/organisations",
+      "headers": {"Content-Type": "application/json", "Accept": "application/json", "X-Request-Id": "2"}},
+    "response": {"status": 200, "headers": {"Content-Type": "application/json", "Accept": "application/json"}}
+  }]
+}`)
+}
+
+func TestYAML_MergeKeysMustMergeMappings(t *testing.T) {
+	given, when, then := PactYAMLTest(t)
+
+	given.
+		a_yaml_pact("interactions: {<<: [1, 2]}")
+
+	when.
+		the_yaml_is_converted_to_json()
+
+	then.
+		a_conversion_error_is_returned()
+}
+
+func TestYAML_RoundTripsJSONPact(t *testing.T) {
+	given, when, then := PactYAMLTest(t)
+
+	given.
+		a_json_pact(`{"consumer":{"name":"consumer"},"interactions":[{"description":"yes","request":{"path":"/1","query":"a=1\nb=2"},"response":{"status":200,"body":{"n":"123","b":false,"f":1e3}}}]}`)
+
+	when.
+		the_json_is_converted_to_yaml().and().
+		the_yaml_is_converted_to_json()
+
+	then.
+		no_conversion_error_is_returned().and().
+		the_yaml_keeps_key_order("consumer", "interactions").and().
+		the_json_is(`{"consumer":{"name":"consumer"},"interactions":[{"description":"yes","request":{"path":"/1","query":"a=1\nb=2"},"response":{"status":200,"body":{"n":"123","b":false,"f":1e3}}}]}`)
+}
+
+func TestYAML_InvalidYAMLIsAnError(t *testing.T) {
+	given, when, then := PactYAMLTest(t)
+
+	given.
+		a_yaml_pact("interactions: [")
+
+	when.
+		the_yaml_is_converted_to_json()
+
+	then.
+		a_conversion_error_is_returned()
+}
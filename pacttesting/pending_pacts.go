@@ -0,0 +1,125 @@
+package pacttesting
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Pact metadata keys marking a pact as pending or work in progress. broker.FetchPacts sets them from the
+// verification properties of the broker; they can also be added to local pact files by hand.
+const (
+	pendingMetadataKey = "pending"
+	wipMetadataKey     = "wip"
+)
+
+// pendingStatus works out whether the pact file is pending or work in progress, from the markers in its metadata
+// or from the PendingPacts allowlist of params. local tells that the pact was marked pending locally: it is
+// allowlisted, or its metadata marker was not set by broker.FetchPacts.
+func pendingStatus(params PactProviderTestParams, topLevelDir, file string) (pending, wip, local bool, err error) {
+	doc, err := readPactDocument(file)
+	if err != nil {
+		return false, false, false, err
+	}
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	wip, _ = metadata[wipMetadataKey].(bool)
+	pending, _ = metadata[pendingMetadataKey].(bool)
+	if pending || wip {
+		return true, wip, !fetchedFromBroker(doc), nil
+	}
+
+	if params.PendingPacts == "" {
+		return false, false, false, nil
+	}
+	allowlist := params.PendingPacts
+	if !filepath.IsAbs(allowlist) {
+		allowlist = filepath.Join(topLevelDir, allowlist)
+	}
+	patterns, err := readPendingPacts(allowlist)
+	if err != nil {
+		return false, false, false, err
+	}
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(pattern, filepath.Base(file))
+		if err != nil {
+			return false, false, false, fmt.Errorf("pending pacts file '%s': bad pattern '%s': %w", allowlist, pattern, err)
+		}
+		if matched {
+			return true, false, true, nil
+		}
+	}
+	return false, false, false, nil
+}
+
+// fetchedFromBroker reports whether the pact was fetched from a pact broker, which links it to its verification
+// results.
+func fetchedFromBroker(doc pactDocument) bool {
+	links, _ := doc["_links"].(map[string]interface{})
+	_, ok := links["pb:publish-verification-results"]
+	return ok
+}
+
+// readPendingPacts reads a pending pacts allowlist: one pact file name pattern per line, with blank lines and lines
+// starting with # ignored. A missing file lists no pacts.
+func readPendingPacts(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading pending pacts file: %w", err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading pending pacts file '%s': %w", path, err)
+	}
+	return patterns, nil
+}
+
+// markPending records on result whether its pact file is pending or work in progress.
+func markPending(params PactProviderTestParams, topLevelDir string, result *VerificationResult) error {
+	pending, wip, local, err := pendingStatus(params, topLevelDir, result.File)
+	if err != nil {
+		return err
+	}
+	result.Pending = pending
+	result.WIP = wip
+	result.pendingLocally = local
+	return nil
+}
+
+// stalePending reports whether the pact passed while marked pending locally. Nothing would stop it being pending,
+// so a later regression would only ever be a warning.
+func (r *VerificationResult) stalePending() bool {
+	return r.Pending && r.pendingLocally && r.Success
+}
+
+// logPendingResult tells whether a pending pact is still failing, or has been verified. A pact marked pending
+// locally that passes fails the test, so that it is no longer marked pending and regressions fail from then on. The
+// broker stops reporting a pact as pending itself once a successful verification is published.
+func logPendingResult(t *testing.T, result *VerificationResult) {
+	t.Helper()
+	switch {
+	case !result.Pending:
+	case result.stalePending():
+		t.Errorf("pending pact %s was verified successfully, remove it from PendingPacts or drop its pending marker",
+			filepath.Base(result.File))
+	case result.Success:
+		t.Logf("pending pact %s was verified successfully, it no longer needs to be pending", filepath.Base(result.File))
+	case !result.blocking():
+		t.Logf("WARNING: pending pact %s has %d failing interactions", filepath.Base(result.File), result.failures())
+	}
+}
@@ -0,0 +1,138 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pendingPactsStage struct {
+	t         *testing.T
+	dir       string
+	pactFile  string
+	params    PactProviderTestParams
+	responses []types.ProviderVerifierResponse
+	verifyErr error
+	result    *VerificationResult
+	summary   *VerificationSummary
+	junit     junitTestSuites
+}
+
+func PendingPactsTest(t *testing.T) (*pendingPactsStage, *pendingPactsStage, *pendingPactsStage) {
+	t.Helper()
+	s := &pendingPactsStage{t: t, dir: t.TempDir()}
+	s.pactFile = filepath.Join(s.dir, "consumera-testservicea.json")
+	require.NoError(t, os.WriteFile(s.pactFile, []byte(providerRequestPact), 0o600))
+	return s, s, s
+}
+
+func (s *pendingPactsStage) and() *pendingPactsStage {
+	return s
+}
+
+func (s *pendingPactsStage) the_pact_metadata_has(key string, value interface{}) *pendingPactsStage {
+	doc, err := readPactDocument(s.pactFile)
+	require.NoError(s.t, err)
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		doc["metadata"] = metadata
+	}
+	metadata[key] = value
+	data, err := marshalPactDocument(doc)
+	require.NoError(s.t, err)
+	require.NoError(s.t, os.WriteFile(s.pactFile, data, 0o600))
+	return s
+}
+
+func (s *pendingPactsStage) a_pending_pacts_file(content string) *pendingPactsStage {
+	require.NoError(s.t, os.WriteFile(filepath.Join(s.dir, "pending-pacts.txt"), []byte(content), 0o600))
+	s.params.PendingPacts = "pending-pacts.txt"
+	return s
+}
+
+func (s *pendingPactsStage) a_missing_pending_pacts_file() *pendingPactsStage {
+	s.params.PendingPacts = "pending-pacts.txt"
+	return s
+}
+
+func (s *pendingPactsStage) verifier_responses_with_a_failure() *pendingPactsStage {
+	require.NoError(s.t, json.Unmarshal([]byte(verifierResponses), &s.responses))
+	s.verifyErr = errors.New("pact-provider-verifier: exit status 1")
+	return s
+}
+
+func (s *pendingPactsStage) the_pact_was_fetched_from_the_broker() *pendingPactsStage {
+	doc, err := readPactDocument(s.pactFile)
+	require.NoError(s.t, err)
+	doc["_links"] = map[string]interface{}{
+		"pb:publish-verification-results": map[string]interface{}{"href": "https://broker.example.com/pacts/abc/verification-results"},
+	}
+	data, err := marshalPactDocument(doc)
+	require.NoError(s.t, err)
+	require.NoError(s.t, os.WriteFile(s.pactFile, data, 0o600))
+	return s
+}
+
+func (s *pendingPactsStage) the_verifier_passed() *pendingPactsStage {
+	s.responses, s.verifyErr = nil, nil
+	return s
+}
+
+func (s *pendingPactsStage) the_verifier_failed_to_run() *pendingPactsStage {
+	s.verifyErr = errors.New("pact-provider-verifier: executable file not found")
+	return s
+}
+
+func (s *pendingPactsStage) the_pact_is_verified() *pendingPactsStage {
+	s.result = newVerificationResult(s.pactFile, time.Now(), s.responses, s.verifyErr)
+	require.NoError(s.t, markPending(s.params, s.dir, s.result))
+	s.summary = newVerificationSummary(providerVersionInfo{version: "1.2.3"}, []*VerificationResult{s.result})
+
+	path := filepath.Join(s.dir, "pact.xml")
+	require.NoError(s.t, s.summary.WriteJUnit(path))
+	data, err := os.ReadFile(path)
+	require.NoError(s.t, err)
+	require.NoError(s.t, xml.Unmarshal(data, &s.junit))
+	return s
+}
+
+func (s *pendingPactsStage) the_pact_is_pending(pending, wip bool) *pendingPactsStage {
+	assert.Equal(s.t, pending, s.result.Pending)
+	assert.Equal(s.t, wip, s.result.WIP)
+	return s
+}
+
+func (s *pendingPactsStage) the_verification_fails(fails bool) *pendingPactsStage {
+	assert.False(s.t, s.result.Success)
+	assert.Equal(s.t, fails, s.result.blocking())
+	assert.Equal(s.t, !fails, s.summary.Success)
+	return s
+}
+
+func (s *pendingPactsStage) the_local_pending_marking_is_stale(stale bool) *pendingPactsStage {
+	assert.True(s.t, s.result.Success)
+	assert.Equal(s.t, stale, s.result.stalePending())
+	return s
+}
+
+func (s *pendingPactsStage) the_summary_has_failures(failures int) *pendingPactsStage {
+	assert.Equal(s.t, failures, s.summary.Failures)
+	return s
+}
+
+func (s *pendingPactsStage) the_junit_report_has(failures, errors, skipped int) *pendingPactsStage {
+	assert.Equal(s.t, failures, s.junit.Failures)
+	assert.Equal(s.t, errors, s.junit.Errors)
+	require.Len(s.t, s.junit.Suites, 1)
+	assert.Equal(s.t, skipped, s.junit.Suites[0].Skipped)
+	return s
+}
@@ -0,0 +1,145 @@
+package pacttesting
+
+import "testing"
+
+func TestPendingPacts_FailuresOfPendingPactsAreWarnings(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		the_pact_metadata_has("pending", true).and().
+		verifier_responses_with_a_failure()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(true, false).and().
+		the_verification_fails(false).and().
+		the_summary_has_failures(0).and().
+		the_junit_report_has(0, 0, 1)
+}
+
+func TestPendingPacts_WIPPactsArePending(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		the_pact_metadata_has("wip", true).and().
+		verifier_responses_with_a_failure()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(true, true).and().
+		the_verification_fails(false)
+}
+
+func TestPendingPacts_PactsListedInPendingPactsFileArePending(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		a_pending_pacts_file("# new expectations of consumera\n\nconsumerb-*.json\nconsumera-*.json\n").and().
+		verifier_responses_with_a_failure()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(true, false).and().
+		the_verification_fails(false)
+}
+
+func TestPendingPacts_UnlistedPactsFail(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		a_pending_pacts_file("consumerb-*.json\n").and().
+		the_pact_metadata_has("pending", false).and().
+		verifier_responses_with_a_failure()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(false, false).and().
+		the_verification_fails(true).and().
+		the_summary_has_failures(1).and().
+		the_junit_report_has(1, 1, 0)
+}
+
+func TestPendingPacts_MissingPendingPactsFileListsNoPacts(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		a_missing_pending_pacts_file().and().
+		verifier_responses_with_a_failure()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(false, false).and().
+		the_verification_fails(true)
+}
+
+func TestPendingPacts_PendingPactsThatCannotBeVerifiedFail(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		the_pact_metadata_has("pending", true).and().
+		the_verifier_failed_to_run()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(true, false).and().
+		the_verification_fails(true).and().
+		the_junit_report_has(0, 1, 0)
+}
+
+func TestPendingPacts_PassingAllowlistedPactsMustBeRemoved(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		a_pending_pacts_file("consumera-*.json\n").and().
+		the_verifier_passed()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(true, false).and().
+		the_local_pending_marking_is_stale(true)
+}
+
+func TestPendingPacts_PassingPactsMarkedPendingByHandMustBeUnmarked(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		the_pact_metadata_has("pending", true).and().
+		the_verifier_passed()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(true, false).and().
+		the_local_pending_marking_is_stale(true)
+}
+
+func TestPendingPacts_PassingPactsPendingOnTheBrokerStayPending(t *testing.T) {
+	given, when, then := PendingPactsTest(t)
+
+	given.
+		the_pact_metadata_has("pending", true).and().
+		the_pact_was_fetched_from_the_broker().and().
+		the_verifier_passed()
+
+	when.
+		the_pact_is_verified()
+
+	then.
+		the_pact_is_pending(true, false).and().
+		the_local_pending_marking_is_stale(false)
+}
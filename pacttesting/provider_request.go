@@ -0,0 +1,338 @@
+package pacttesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pact-foundation/pact-go/proxy"
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestFilter intercepts every request the verifier sends to the provider, like RequestFilter of pact-go's
+// types.VerifyRequest: it wraps the handler that forwards to the provider and may change the request before
+// calling next, e.g. to set a freshly minted token or an HMAC signature of the body. Static Headers have already
+// been applied when the filter runs.
+//
+// Anything a filter changes is not captured in the contract, so it should be used sparingly.
+type RequestFilter = proxy.Middleware
+
+// InteractionHeaders adds Headers to the requests of the interactions Selector picks, e.g. to verify an
+// interaction with the credentials of a user that its provider state sets up.
+type InteractionHeaders struct {
+	Selector InteractionSelector
+	Headers  http.Header
+}
+
+// customProviderHeaders returns the static headers for the pacts of consumer in the "Name: value" form the verifier
+// expects. AuthToken is sent as a bearer token unless Headers or ConsumerHeaders set Authorization themselves.
+func customProviderHeaders(params PactProviderTestParams, consumer string) []string {
+	headers := http.Header{}
+	if params.AuthToken != "" {
+		headers.Set("Authorization", "Bearer "+params.AuthToken)
+	}
+	replaceHeaders(headers, params.Headers)
+	replaceHeaders(headers, params.ConsumerHeaders[consumer])
+
+	var custom []string
+	for _, name := range sortedKeys(headers) {
+		for _, value := range headers[name] {
+			custom = append(custom, name+": "+value)
+		}
+	}
+	return custom
+}
+
+// replaceHeaders sets every header of overrides in headers, replacing any values it had.
+func replaceHeaders(headers, overrides http.Header) {
+	for name, values := range overrides {
+		headers.Del(name)
+		for _, value := range values {
+			headers.Add(name, value)
+		}
+	}
+}
+
+// providerTarget is where the verifier sends the requests for one pact file. Proxies are started in front of the
+// provider when requests need per-interaction headers or a request filter.
+type providerTarget struct {
+	baseURL       string
+	stateSetupURL string
+	headers       []string
+	stops         []func()
+}
+
+func newProviderTarget(params PactProviderTestParams, pactFile string) (*providerTarget, error) {
+	if params.Handler != nil && params.BaseURL != "" {
+		return nil, errors.New("BaseURL and Handler cannot be used together")
+	}
+	doc, err := readPactDocument(pactFile)
+	if err != nil {
+		return nil, err
+	}
+	target := &providerTarget{
+		baseURL:       params.BaseURL,
+		stateSetupURL: params.ProviderStateSetupURL,
+		headers:       customProviderHeaders(params, participantName(doc, "consumer")),
+	}
+
+	var filters []RequestFilter
+	var tracker *interactionTracker
+	if len(params.InteractionHeaders) > 0 {
+		tracker = &interactionTracker{
+			interactions: documentInteractions(doc, "interactions"),
+			rules:        params.InteractionHeaders,
+		}
+		filters = append(filters, tracker.addHeaders)
+	}
+
+	switch {
+	case len(params.StateHandlers) > 0 || len(params.StateTeardownHandlers) > 0:
+		if params.ProviderStateSetupURL != "" {
+			return nil, errors.New("ProviderStateSetupURL and StateHandlers cannot be used together")
+		}
+		if err := target.serveStates(params, tracker); err != nil {
+			target.close()
+			return nil, err
+		}
+	case tracker != nil && params.ProviderStateSetupURL != "":
+		if err := target.proxyStateSetup(tracker.observeStates); err != nil {
+			target.close()
+			return nil, err
+		}
+	}
+	if params.RequestFilter != nil {
+		filters = append(filters, params.RequestFilter)
+	}
+
+	var baseURL string
+	var stop func()
+	switch {
+	case params.Handler != nil:
+		handler := params.Handler
+		if len(filters) > 0 {
+			handler = chainFilters(filters)(handler)
+		}
+		baseURL, stop, err = startHandlerServer(handler)
+	case len(filters) > 0:
+		baseURL, stop, err = startFilterProxy(params.BaseURL, chainFilters(filters))
+	default:
+		return target, nil
+	}
+	if err != nil {
+		target.close()
+		return nil, err
+	}
+	target.baseURL = baseURL
+	target.stops = append(target.stops, stop)
+	return target, nil
+}
+
+// serveStates starts the provider state setup endpoint for the StateHandlers of params.
+func (p *providerTarget) serveStates(params PactProviderTestParams, tracker *interactionTracker) error {
+	handler := providerStateHandler(params.StateHandlers, params.StateTeardownHandlers)
+	if tracker != nil {
+		handler = tracker.observeStates(handler)
+	}
+	baseURL, stop, err := startLocalServer(handler, "provider state server")
+	if err != nil {
+		return err
+	}
+	p.stops = append(p.stops, stop)
+	p.stateSetupURL = baseURL + providerStatesSetupPath
+	return nil
+}
+
+// proxyStateSetup routes the provider state setup calls through filter.
+func (p *providerTarget) proxyStateSetup(filter RequestFilter) error {
+	setupURL, err := url.Parse(p.stateSetupURL)
+	if err != nil {
+		return fmt.Errorf("parsing provider state setup URL '%s': %w", p.stateSetupURL, err)
+	}
+	origin := url.URL{Scheme: setupURL.Scheme, Host: setupURL.Host}
+	baseURL, stop, err := startFilterProxy(origin.String(), filter)
+	if err != nil {
+		return err
+	}
+	p.stops = append(p.stops, stop)
+
+	proxyURL, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("parsing proxy URL '%s': %w", baseURL, err)
+	}
+	setupURL.Scheme = proxyURL.Scheme
+	setupURL.Host = proxyURL.Host
+	p.stateSetupURL = setupURL.String()
+	return nil
+}
+
+func (p *providerTarget) close() {
+	for _, stop := range p.stops {
+		stop()
+	}
+}
+
+// chainFilters combines filters into one, the first being outermost.
+func chainFilters(filters []RequestFilter) RequestFilter {
+	return func(next http.Handler) http.Handler {
+		for i := len(filters) - 1; i >= 0; i-- {
+			next = filters[i](next)
+		}
+		return next
+	}
+}
+
+// interactionTracker works out which interaction of a pact file a verified request belongs to and adds the
+// headers configured for it. Interactions are told apart by their request line, and by the provider states last
+// set up when several interactions send the same request.
+type interactionTracker struct {
+	interactions []map[string]interface{}
+	rules        []InteractionHeaders
+
+	mu     sync.Mutex
+	states []string
+}
+
+func (t *interactionTracker) observeStates(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "reading provider state request: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var setup providerStateRequest
+		if err := json.Unmarshal(body, &setup); err != nil {
+			log.WithError(err).Warn("unable to decode provider state request")
+		}
+		t.mu.Lock()
+		if setup.teardown() {
+			t.states = nil
+		} else {
+			t.states = setup.names()
+		}
+		t.mu.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (t *interactionTracker) addHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if interaction := t.interactionFor(r); interaction != nil {
+			for _, rule := range t.rules {
+				if rule.Selector(interaction) {
+					replaceHeaders(r.Header, rule.Headers)
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// interactionFor returns the interaction r was sent for, or nil if it matches none.
+func (t *interactionTracker) interactionFor(r *http.Request) map[string]interface{} {
+	var candidates []map[string]interface{}
+	for _, interaction := range t.interactions {
+		if requestMatches(interaction, r) {
+			candidates = append(candidates, interaction)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	states := t.states
+	t.mu.Unlock()
+	for _, candidate := range candidates {
+		if strings.Join(providerStateNames(candidate), "\n") == strings.Join(states, "\n") {
+			return candidate
+		}
+	}
+	return candidates[0]
+}
+
+// requestMatches reports whether r is the request of interaction, comparing method, path and query.
+func requestMatches(interaction map[string]interface{}, r *http.Request) bool {
+	request, _ := interaction["request"].(map[string]interface{})
+	method, _ := request["method"].(string)
+	path, _ := request["path"].(string)
+	if !strings.EqualFold(method, r.Method) || (path != r.URL.Path && path != r.URL.EscapedPath()) {
+		return false
+	}
+
+	expected := queryValues(request["query"])
+	actual := r.URL.Query()
+	if len(expected) != len(actual) {
+		return false
+	}
+	for name, values := range expected {
+		list, _ := values.([]interface{})
+		if len(list) != len(actual[name]) {
+			return false
+		}
+		for i, value := range list {
+			if value != actual[name][i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// startFilterProxy starts a reverse proxy to target that passes every request through filter. It returns the
+// base URL of the proxy and a function that stops it.
+func startFilterProxy(target string, filter RequestFilter) (string, func(), error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing provider base URL '%s': %w", target, err)
+	}
+	return startLocalServer(filter(httputil.NewSingleHostReverseProxy(targetURL)), "request filter proxy for "+target)
+}
+
+// startHandlerServer serves the provider handler with an httptest.Server on the bind address. It returns the base
+// URL of the server and a function that stops it.
+func startHandlerServer(handler http.Handler) (string, func(), error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(getBindAddress(), "0"))
+	if err != nil {
+		return "", nil, fmt.Errorf("starting provider handler server: %w", err)
+	}
+	server := httptest.NewUnstartedServer(handler)
+	_ = server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	return server.URL, server.Close, nil
+}
+
+// startLocalServer serves handler on a free port of the bind address. It returns the base URL of the server and a
+// function that stops it.
+func startLocalServer(handler http.Handler, name string) (string, func(), error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(getBindAddress(), "0"))
+	if err != nil {
+		return "", nil, fmt.Errorf("starting %s: %w", name, err)
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Errorf("%s stopped", name)
+		}
+	}()
+
+	return providerHTTPScheme + listener.Addr().String(), func() { _ = server.Close() }, nil
+}
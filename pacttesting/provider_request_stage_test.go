@@ -0,0 +1,230 @@
+package pacttesting
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/dsl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const providerRequestPact = `{
+  "consumer": {"name": "consumera"},
+  "provider": {"name": "testservicea"},
+  "interactions": [
+    {
+      "description": "get an organisation as an admin",
+      "providerState": "an admin exists",
+      "request": {"method": "GET", "path": "/v1/organisations", "query": "limit=1"},
+      "response": {"status": 200}
+    },
+    {
+      "description": "get an organisation as a reader",
+      "providerState": "a reader exists",
+      "request": {"method": "GET", "path": "/v1/organisations", "query": "limit=1"},
+      "response": {"status": 200}
+    }
+  ]
+}`
+
+type providerRequestStage struct {
+	t        *testing.T
+	params   PactProviderTestParams
+	pactFile string
+	provider *httptest.Server
+	received *http.Request
+	target   *providerTarget
+	body     string
+
+	responseHeader http.Header
+	messageHeaders []string
+	err            error
+}
+
+func ProviderRequestTest(t *testing.T) (*providerRequestStage, *providerRequestStage, *providerRequestStage) {
+	t.Helper()
+	s := &providerRequestStage{t: t}
+	s.pactFile = filepath.Join(t.TempDir(), "testservicea.json")
+	require.NoError(t, os.WriteFile(s.pactFile, []byte(providerRequestPact), 0o600))
+	t.Cleanup(func() {
+		if s.target != nil {
+			s.target.close()
+		}
+		if s.provider != nil {
+			s.provider.Close()
+		}
+	})
+	return s, s, s
+}
+
+func (s *providerRequestStage) and() *providerRequestStage {
+	return s
+}
+
+func (s *providerRequestStage) a_provider() *providerRequestStage {
+	s.provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pact-setup" {
+			s.received = r
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	s.params.BaseURL = s.provider.URL
+	return s
+}
+
+func (s *providerRequestStage) a_provider_state_setup_url() *providerRequestStage {
+	s.params.ProviderStateSetupURL = s.provider.URL + "/pact-setup"
+	return s
+}
+
+func (s *providerRequestStage) an_auth_token(token string) *providerRequestStage {
+	s.params.AuthToken = token
+	return s
+}
+
+func (s *providerRequestStage) static_headers(headers http.Header) *providerRequestStage {
+	s.params.Headers = headers
+	return s
+}
+
+func (s *providerRequestStage) consumer_headers(consumer string, headers http.Header) *providerRequestStage {
+	if s.params.ConsumerHeaders == nil {
+		s.params.ConsumerHeaders = map[string]http.Header{}
+	}
+	s.params.ConsumerHeaders[consumer] = headers
+	return s
+}
+
+func (s *providerRequestStage) interaction_headers(selector InteractionSelector, headers http.Header) *providerRequestStage {
+	s.params.InteractionHeaders = append(s.params.InteractionHeaders, InteractionHeaders{Selector: selector, Headers: headers})
+	return s
+}
+
+func (s *providerRequestStage) a_request_filter_setting(name, value string) *providerRequestStage {
+	s.params.RequestFilter = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Set(name, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+	return s
+}
+
+func (s *providerRequestStage) the_provider_target_is_prepared() *providerRequestStage {
+	target, err := newProviderTarget(s.params, s.pactFile)
+	require.NoError(s.t, err)
+	s.target = target
+	return s
+}
+
+func (s *providerRequestStage) the_provider_state_is_set_up(state string) *providerRequestStage {
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, s.target.stateSetupURL,
+		bytes.NewReader([]byte(`{"consumer": "consumera", "state": "`+state+`", "states": ["`+state+`"]}`)))
+	require.NoError(s.t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.t, err)
+	defer res.Body.Close()
+	assert.Equal(s.t, http.StatusOK, res.StatusCode)
+	return s
+}
+
+func (s *providerRequestStage) a_request_is_sent_to_the_provider() *providerRequestStage {
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, s.target.baseURL+"/v1/organisations?limit=1", nil)
+	require.NoError(s.t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(s.t, err)
+	s.body = string(body)
+	s.responseHeader = res.Header
+	return s
+}
+
+func (s *providerRequestStage) the_message_provider_headers_are_prepared() *providerRequestStage {
+	s.messageHeaders, s.err = messageProviderHeaders(s.params, dsl.VerifyMessageRequest{PactURLs: []string{s.pactFile}})
+	return s
+}
+
+func (s *providerRequestStage) the_message_provider_headers_are(expected ...string) *providerRequestStage {
+	require.NoError(s.t, s.err)
+	assert.Equal(s.t, expected, s.messageHeaders)
+	return s
+}
+
+func (s *providerRequestStage) preparing_the_headers_fails_with(message string) *providerRequestStage {
+	require.Error(s.t, s.err)
+	assert.Contains(s.t, s.err.Error(), message)
+	return s
+}
+
+func (s *providerRequestStage) the_custom_provider_headers_are(expected ...string) *providerRequestStage {
+	assert.Equal(s.t, expected, s.target.headers)
+	return s
+}
+
+func (s *providerRequestStage) the_verifier_talks_to_the_provider_directly() *providerRequestStage {
+	assert.Equal(s.t, s.provider.URL, s.target.baseURL)
+	assert.Equal(s.t, s.params.ProviderStateSetupURL, s.target.stateSetupURL)
+	return s
+}
+
+func (s *providerRequestStage) the_provider_received_header(name, value string) *providerRequestStage {
+	require.NotNil(s.t, s.received, "provider received no request")
+	assert.Equal(s.t, value, s.received.Header.Get(name))
+	assert.Equal(s.t, "/v1/organisations", s.received.URL.Path)
+	assert.Equal(s.t, "ok", s.body)
+	return s
+}
+
+func (s *providerRequestStage) a_provider_handler() *providerRequestStage {
+	s.params.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.received = r
+		_, _ = w.Write([]byte("ok"))
+	})
+	return s
+}
+
+func (s *providerRequestStage) a_response_filter_setting(name, value string) *providerRequestStage {
+	s.params.RequestFilter = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(name, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+	return s
+}
+
+func (s *providerRequestStage) the_handler_server_is_stopped() *providerRequestStage {
+	s.target.close()
+	return s
+}
+
+func (s *providerRequestStage) the_response_had_header(name, value string) *providerRequestStage {
+	assert.Equal(s.t, value, s.responseHeader.Get(name))
+	return s
+}
+
+func (s *providerRequestStage) the_provider_can_no_longer_be_reached() *providerRequestStage {
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, s.target.baseURL+"/v1/organisations", nil)
+	require.NoError(s.t, err)
+	res, err := http.DefaultClient.Do(req)
+	if err == nil {
+		res.Body.Close()
+	}
+	assert.Error(s.t, err)
+	return s
+}
+
+func (s *providerRequestStage) preparing_the_target_fails() *providerRequestStage {
+	_, err := newProviderTarget(s.params, s.pactFile)
+	assert.Error(s.t, err)
+	return s
+}
@@ -0,0 +1,190 @@
+package pacttesting
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProviderRequest_AuthTokenIsSentAsBearerToken(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		an_auth_token("token")
+
+	when.
+		the_provider_target_is_prepared()
+
+	then.
+		the_custom_provider_headers_are("Authorization: Bearer token")
+}
+
+func TestProviderRequest_StaticHeadersAreAdded(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		an_auth_token("token").and().
+		static_headers(http.Header{"x-api-key": {"key"}, "Authorization": {"Basic cGFjdDpwYWN0"}})
+
+	when.
+		the_provider_target_is_prepared()
+
+	then.
+		the_custom_provider_headers_are("Authorization: Basic cGFjdDpwYWN0", "X-Api-Key: key")
+}
+
+func TestProviderRequest_NoHeadersWithoutAuthToken(t *testing.T) {
+	_, when, then := ProviderRequestTest(t)
+
+	when.
+		the_provider_target_is_prepared()
+
+	then.
+		the_custom_provider_headers_are()
+}
+
+func TestProviderRequest_ConsumerHeadersAreAdded(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		an_auth_token("token").and().
+		static_headers(http.Header{"X-Api-Key": {"key"}}).and().
+		consumer_headers("consumera", http.Header{"Authorization": {"Bearer consumera-token"}}).and().
+		consumer_headers("consumerb", http.Header{"X-Api-Key": {"consumerb-key"}})
+
+	when.
+		the_provider_target_is_prepared()
+
+	then.
+		the_custom_provider_headers_are("Authorization: Bearer consumera-token", "X-Api-Key: key")
+}
+
+func TestProviderRequest_ConsumerHeadersAreAddedToMessagePacts(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		static_headers(http.Header{"X-Api-Key": {"key"}}).and().
+		consumer_headers("consumera", http.Header{"Authorization": {"Bearer consumera-token"}}).and().
+		consumer_headers("consumerb", http.Header{"X-Api-Key": {"consumerb-key"}})
+
+	when.
+		the_message_provider_headers_are_prepared()
+
+	then.
+		the_message_provider_headers_are("Authorization: Bearer consumera-token", "X-Api-Key: key")
+}
+
+func TestProviderRequest_InteractionHeadersAreRejectedForMessagePacts(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		interaction_headers(WithProviderState("an admin exists"), http.Header{"Authorization": {"Bearer admin"}})
+
+	when.
+		the_message_provider_headers_are_prepared()
+
+	then.
+		preparing_the_headers_fails_with("InteractionHeaders cannot be used to verify message pacts")
+}
+
+func TestProviderRequest_VerifierTalksToProviderWithoutFilter(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		a_provider().and().
+		a_provider_state_setup_url()
+
+	when.
+		the_provider_target_is_prepared()
+
+	then.
+		the_verifier_talks_to_the_provider_directly()
+}
+
+func TestProviderRequest_RequestFilterIsApplied(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		a_provider().and().
+		a_request_filter_setting("X-Signature", "signed")
+
+	when.
+		the_provider_target_is_prepared().and().
+		a_request_is_sent_to_the_provider()
+
+	then.
+		the_provider_received_header("X-Signature", "signed")
+}
+
+func TestProviderRequest_InteractionHeadersFollowProviderState(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		a_provider().and().
+		a_provider_state_setup_url().and().
+		interaction_headers(WithProviderState("an admin exists"), http.Header{"Authorization": {"Bearer admin"}}).and().
+		interaction_headers(WithProviderState("a reader exists"), http.Header{"Authorization": {"Bearer reader"}})
+
+	when.
+		the_provider_target_is_prepared().and().
+		the_provider_state_is_set_up("a reader exists").and().
+		a_request_is_sent_to_the_provider()
+
+	then.
+		the_provider_received_header("Authorization", "Bearer reader")
+
+	when.
+		the_provider_state_is_set_up("an admin exists").and().
+		a_request_is_sent_to_the_provider()
+
+	then.
+		the_provider_received_header("Authorization", "Bearer admin")
+}
+
+func TestProviderRequest_InteractionHeadersRunBeforeRequestFilter(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		a_provider().and().
+		interaction_headers(WithDescription("get an organisation as an admin"), http.Header{"X-Role": {"admin"}}).and().
+		a_request_filter_setting("X-Role", "filtered")
+
+	when.
+		the_provider_target_is_prepared().and().
+		a_request_is_sent_to_the_provider()
+
+	then.
+		the_provider_received_header("X-Role", "filtered")
+}
+
+func TestProviderRequest_HandlerIsVerifiedInProcess(t *testing.T) {
+	given, when, then := ProviderRequestTest(t)
+
+	given.
+		a_provider_handler().and().
+		a_response_filter_setting("X-Verified", "true")
+
+	when.
+		the_provider_target_is_prepared().and().
+		a_request_is_sent_to_the_provider()
+
+	then.
+		the_provider_received_header("X-Verified", "").and().
+		the_response_had_header("X-Verified", "true")
+
+	when.
+		the_handler_server_is_stopped()
+
+	then.
+		the_provider_can_no_longer_be_reached()
+}
+
+func TestProviderRequest_HandlerCannotBeCombinedWithBaseURL(t *testing.T) {
+	given, _, then := ProviderRequestTest(t)
+
+	given.
+		a_provider().and().
+		a_provider_handler()
+
+	then.
+		preparing_the_target_fails()
+}
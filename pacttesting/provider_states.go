@@ -0,0 +1,80 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pact-foundation/pact-go/dsl"
+	log "github.com/sirupsen/logrus"
+)
+
+// providerStatesSetupPath is the path of the provider state setup endpoint run for StateHandlers.
+const providerStatesSetupPath = "/__setup"
+
+// providerStateRequest is what the verifier posts to the provider state setup URL: a single provider state with its
+// params, or the names of all provider states of the interaction.
+type providerStateRequest struct {
+	Consumer string                 `json:"consumer"`
+	State    string                 `json:"state"`
+	States   []string               `json:"states"`
+	Params   map[string]interface{} `json:"params"`
+	Action   string                 `json:"action"`
+}
+
+func (r providerStateRequest) teardown() bool {
+	return r.Action == "teardown"
+}
+
+// names returns the names of the provider states the request is for.
+func (r providerStateRequest) names() []string {
+	if r.State != "" {
+		return []string{r.State}
+	}
+	return r.States
+}
+
+func (r providerStateRequest) providerStates() []dsl.State {
+	if r.State != "" {
+		return []dsl.State{{Name: r.State, Params: r.Params}}
+	}
+	states := make([]dsl.State, 0, len(r.States))
+	for _, name := range r.States {
+		states = append(states, dsl.State{Name: name})
+	}
+	return states
+}
+
+// providerStateHandler serves provider state setup calls by running the handler for each provider state, or the
+// teardown handler once the verifier has verified the interaction. Provider states without a handler are logged and
+// otherwise ignored, like pact-go does.
+func providerStateHandler(setup, teardown dsl.StateHandlers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request providerStateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "decoding provider state request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		handlers, action := setup, "setting up"
+		if request.teardown() {
+			handlers, action = teardown, "tearing down"
+		}
+		for _, state := range request.providerStates() {
+			handler, ok := handlers[state.Name]
+			if !ok {
+				if !request.teardown() {
+					log.Warnf("no state handler for provider state '%s' of %s", state.Name, request.Consumer)
+				}
+				continue
+			}
+			if err := handler(state); err != nil {
+				log.WithError(err).Errorf("%s provider state '%s' failed", action, state.Name)
+				http.Error(w, fmt.Sprintf("%s provider state '%s': %v", action, state.Name, err),
+					http.StatusInternalServerError)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
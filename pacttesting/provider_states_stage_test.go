@@ -0,0 +1,118 @@
+package pacttesting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/dsl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerStatesStage struct {
+	t         *testing.T
+	params    PactProviderTestParams
+	pactFile  string
+	target    *providerTarget
+	targetErr error
+	calls     []string
+	status    int
+}
+
+func ProviderStatesTest(t *testing.T) (*providerStatesStage, *providerStatesStage, *providerStatesStage) {
+	t.Helper()
+	s := &providerStatesStage{t: t}
+	s.pactFile = filepath.Join(t.TempDir(), "testservicea.json")
+	require.NoError(t, os.WriteFile(s.pactFile, []byte(providerRequestPact), 0o600))
+	t.Cleanup(func() {
+		if s.target != nil {
+			s.target.close()
+		}
+	})
+	return s, s, s
+}
+
+func (s *providerStatesStage) and() *providerStatesStage {
+	return s
+}
+
+func (s *providerStatesStage) a_state_handler(name string) *providerStatesStage {
+	if s.params.StateHandlers == nil {
+		s.params.StateHandlers = dsl.StateHandlers{}
+	}
+	s.params.StateHandlers[name] = func(state dsl.State) error {
+		s.calls = append(s.calls, "setup "+state.Name+" "+canonicalJSON(state.Params))
+		return nil
+	}
+	return s
+}
+
+func (s *providerStatesStage) a_failing_state_handler(name string) *providerStatesStage {
+	if s.params.StateHandlers == nil {
+		s.params.StateHandlers = dsl.StateHandlers{}
+	}
+	s.params.StateHandlers[name] = func(dsl.State) error {
+		return errors.New("database unavailable")
+	}
+	return s
+}
+
+func (s *providerStatesStage) a_teardown_handler(name string) *providerStatesStage {
+	s.params.StateTeardownHandlers = dsl.StateHandlers{
+		name: func(state dsl.State) error {
+			s.calls = append(s.calls, "teardown "+state.Name)
+			return nil
+		},
+	}
+	return s
+}
+
+func (s *providerStatesStage) a_provider_state_setup_url() *providerStatesStage {
+	s.params.ProviderStateSetupURL = "http://localhost:8080/pact-setup"
+	return s
+}
+
+func (s *providerStatesStage) the_provider_target_is_prepared() *providerStatesStage {
+	s.target, s.targetErr = newProviderTarget(s.params, s.pactFile)
+	return s
+}
+
+func (s *providerStatesStage) the_verifier_posts(body string) *providerStatesStage {
+	require.NoError(s.t, s.targetErr)
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, s.target.stateSetupURL,
+		bytes.NewReader([]byte(body)))
+	require.NoError(s.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.t, err)
+	defer res.Body.Close()
+	s.status = res.StatusCode
+	return s
+}
+
+func (s *providerStatesStage) the_verifier_is_pointed_at_the_state_server() *providerStatesStage {
+	require.NoError(s.t, s.targetErr)
+	assert.True(s.t, strings.HasSuffix(s.target.stateSetupURL, providerStatesSetupPath), s.target.stateSetupURL)
+	return s
+}
+
+func (s *providerStatesStage) the_handlers_were_called(expected ...string) *providerStatesStage {
+	assert.Equal(s.t, expected, s.calls)
+	return s
+}
+
+func (s *providerStatesStage) the_state_server_responds(status int) *providerStatesStage {
+	assert.Equal(s.t, status, s.status)
+	return s
+}
+
+func (s *providerStatesStage) preparing_the_target_fails() *providerStatesStage {
+	assert.Error(s.t, s.targetErr)
+	return s
+}
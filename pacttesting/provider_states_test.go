@@ -0,0 +1,82 @@
+package pacttesting
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProviderStates_StateHandlerIsCalledWithParams(t *testing.T) {
+	given, when, then := ProviderStatesTest(t)
+
+	given.
+		a_state_handler("an organisation exists")
+
+	when.
+		the_provider_target_is_prepared().and().
+		the_verifier_posts(`{"consumer": "consumera", "state": "an organisation exists", "params": {"id": "743d5b63"}}`)
+
+	then.
+		the_verifier_is_pointed_at_the_state_server().and().
+		the_state_server_responds(http.StatusOK).and().
+		the_handlers_were_called(`setup an organisation exists {"id":"743d5b63"}`)
+}
+
+func TestProviderStates_EveryNamedStateIsSetUp(t *testing.T) {
+	given, when, then := ProviderStatesTest(t)
+
+	given.
+		a_state_handler("an admin exists").and().
+		a_state_handler("an organisation exists")
+
+	when.
+		the_provider_target_is_prepared().and().
+		the_verifier_posts(`{"consumer": "consumera", "states": ["an admin exists", "an unknown state", "an organisation exists"]}`)
+
+	then.
+		the_state_server_responds(http.StatusOK).and().
+		the_handlers_were_called("setup an admin exists null", "setup an organisation exists null")
+}
+
+func TestProviderStates_TeardownHandlerIsCalled(t *testing.T) {
+	given, when, then := ProviderStatesTest(t)
+
+	given.
+		a_state_handler("an organisation exists").and().
+		a_teardown_handler("an organisation exists")
+
+	when.
+		the_provider_target_is_prepared().and().
+		the_verifier_posts(`{"consumer": "consumera", "state": "an organisation exists", "action": "setup"}`).and().
+		the_verifier_posts(`{"consumer": "consumera", "state": "an organisation exists", "action": "teardown"}`)
+
+	then.
+		the_handlers_were_called("setup an organisation exists null", "teardown an organisation exists")
+}
+
+func TestProviderStates_FailingHandlerFailsTheSetup(t *testing.T) {
+	given, when, then := ProviderStatesTest(t)
+
+	given.
+		a_failing_state_handler("an organisation exists")
+
+	when.
+		the_provider_target_is_prepared().and().
+		the_verifier_posts(`{"consumer": "consumera", "state": "an organisation exists"}`)
+
+	then.
+		the_state_server_responds(http.StatusInternalServerError)
+}
+
+func TestProviderStates_CannotBeCombinedWithSetupURL(t *testing.T) {
+	given, when, then := ProviderStatesTest(t)
+
+	given.
+		a_state_handler("an organisation exists").and().
+		a_provider_state_setup_url()
+
+	when.
+		the_provider_target_is_prepared()
+
+	then.
+		preparing_the_target_fails()
+}
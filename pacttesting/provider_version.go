@@ -0,0 +1,150 @@
+package pacttesting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+)
+
+// VersionSource supplies the provider version recorded with verification results. It returns an empty version when
+// it has none to offer, so that the next source is tried.
+type VersionSource func() (string, error)
+
+// VersionFromEnv reads the provider version from the environment variable name.
+func VersionFromEnv(name string) VersionSource {
+	return func() (string, error) {
+		return strings.TrimSpace(os.Getenv(name)), nil
+	}
+}
+
+// VersionFromGitDescribe uses the output of git describe --tags, which requires the repository to have a tag.
+func VersionFromGitDescribe() VersionSource {
+	return func() (string, error) {
+		return gitOutput("describe", "--tags")
+	}
+}
+
+// VersionFromGitSHA uses the commit SHA of the checked out git revision.
+func VersionFromGitSHA() VersionSource {
+	return func() (string, error) {
+		return gitOutput("rev-parse", "HEAD")
+	}
+}
+
+// VersionFromBuildInfo uses the version of the main module embedded in the running binary, or the VCS revision it
+// was built from. Test binaries usually carry neither.
+func VersionFromBuildInfo() VersionSource {
+	return func() (string, error) {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return "", nil
+		}
+		if info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version, nil
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value, nil
+			}
+		}
+		return "", nil
+	}
+}
+
+// defaultVersionSources are tried when neither ProviderVersion nor VersionSources are given.
+func defaultVersionSources() []VersionSource {
+	return []VersionSource{
+		VersionFromEnv("PACT_PROVIDER_VERSION"),
+		VersionFromGitDescribe(),
+		VersionFromGitSHA(),
+		VersionFromBuildInfo(),
+	}
+}
+
+// providerVersionInfo is the provider version and build metadata recorded with verification results.
+type providerVersionInfo struct {
+	version  string
+	branch   string
+	buildURL string
+}
+
+func resolveProviderVersion(params PactProviderTestParams) (providerVersionInfo, error) {
+	info := providerVersionInfo{
+		version:  params.ProviderVersion,
+		branch:   params.ProviderBranch,
+		buildURL: params.BuildURL,
+	}
+
+	if info.version == "" {
+		sources := params.VersionSources
+		if len(sources) == 0 {
+			sources = defaultVersionSources()
+		}
+		var errs []error
+		for _, source := range sources {
+			version, err := source()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if version != "" {
+				info.version = version
+				break
+			}
+		}
+		if info.version == "" {
+			return info, fmt.Errorf("no provider version found, set ProviderVersion: %w", errors.Join(errs...))
+		}
+	}
+
+	if info.branch == "" {
+		info.branch = providerBranch()
+	}
+	if info.buildURL == "" {
+		info.buildURL = buildURL()
+	}
+	return info, nil
+}
+
+// providerBranch returns the branch from PACT_PROVIDER_BRANCH or the checked out git branch, if there is one.
+func providerBranch() string {
+	if branch := os.Getenv("PACT_PROVIDER_BRANCH"); branch != "" {
+		return branch
+	}
+	branch, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+// buildURL returns the URL of the CI build running the verification, from PACT_BUILD_URL or the variables set by
+// common CI servers.
+func buildURL() string {
+	for _, name := range []string{"PACT_BUILD_URL", "BUILD_URL", "CI_JOB_URL", "TRAVIS_BUILD_WEB_URL"} {
+		if url := os.Getenv(name); url != "" {
+			return url
+		}
+	}
+	if server, repository, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"),
+		os.Getenv("GITHUB_RUN_ID"); server != "" && repository != "" && run != "" {
+		return server + "/" + repository + "/actions/runs/" + run
+	}
+	return ""
+}
+
+func gitOutput(args ...string) (string, error) {
+	gitCommand := exec.Command("git", args...)
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	gitCommand.Stdout = &out
+	gitCommand.Stderr = &errOut
+	if err := gitCommand.Run(); err != nil {
+		return "", fmt.Errorf("running git %s: %w; out: %s", strings.Join(args, " "), err, errOut.String())
+	}
+	return strings.TrimRight(out.String(), "\n"), nil
+}
@@ -0,0 +1,153 @@
+package pacttesting
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerVersionStage struct {
+	t       *testing.T
+	params  PactProviderTestParams
+	info    providerVersionInfo
+	err     error
+	dir     string
+	written map[string]interface{}
+	rootDir string
+}
+
+func ProviderVersionTest(t *testing.T) (*providerVersionStage, *providerVersionStage, *providerVersionStage) {
+	t.Helper()
+	for _, name := range []string{
+		"PACT_PROVIDER_BRANCH", "PACT_BUILD_URL", "BUILD_URL", "CI_JOB_URL", "TRAVIS_BUILD_WEB_URL",
+		"GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_RUN_ID",
+	} {
+		t.Setenv(name, "")
+	}
+	s := &providerVersionStage{t: t, dir: t.TempDir()}
+	return s, s, s
+}
+
+func (s *providerVersionStage) and() *providerVersionStage {
+	return s
+}
+
+func (s *providerVersionStage) a_provider_version(version string) *providerVersionStage {
+	s.params.ProviderVersion = version
+	return s
+}
+
+func (s *providerVersionStage) version_sources(sources ...VersionSource) *providerVersionStage {
+	s.params.VersionSources = sources
+	return s
+}
+
+func (s *providerVersionStage) a_branch_and_build_url(branch, buildURL string) *providerVersionStage {
+	s.params.ProviderBranch = branch
+	s.params.BuildURL = buildURL
+	return s
+}
+
+func (s *providerVersionStage) the_environment_variable(name, value string) *providerVersionStage {
+	s.t.Setenv(name, value)
+	return s
+}
+
+func (s *providerVersionStage) a_root_dir(dir string) *providerVersionStage {
+	s.params.RootDir = dir
+	return s
+}
+
+func (s *providerVersionStage) the_working_directory_is_not_a_git_checkout() *providerVersionStage {
+	cwd, err := os.Getwd()
+	require.NoError(s.t, err)
+	require.NoError(s.t, os.Chdir(s.dir))
+	s.t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	// keep git from finding a checkout above the temporary directory
+	s.t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(s.dir))
+	_, err = os.Stat(filepath.Join(s.dir, ".git"))
+	require.ErrorIs(s.t, err, os.ErrNotExist)
+	return s
+}
+
+func (s *providerVersionStage) the_root_dir_is_resolved() *providerVersionStage {
+	s.rootDir, s.err = providerRootDir(s.params)
+	return s
+}
+
+func (s *providerVersionStage) the_provider_version_is_resolved() *providerVersionStage {
+	s.info, s.err = resolveProviderVersion(s.params)
+	return s
+}
+
+func (s *providerVersionStage) the_verification_is_written() *providerVersionStage {
+	require.NoError(s.t, writeVerification(s.dir, "testservicea.json", s.info, &VerificationResult{Success: true}, nil))
+	data, err := os.ReadFile(filepath.Join(s.dir, "build", "pact-verifications", "testservicea.json"))
+	require.NoError(s.t, err)
+	doc, err := parsePactDocument(data)
+	require.NoError(s.t, err)
+	s.written = doc
+	return s
+}
+
+func (s *providerVersionStage) the_version_is(version string) *providerVersionStage {
+	require.NoError(s.t, s.err)
+	assert.Equal(s.t, version, s.info.version)
+	return s
+}
+
+func (s *providerVersionStage) the_branch_and_build_url_are(branch, buildURL string) *providerVersionStage {
+	assert.Equal(s.t, branch, s.info.branch)
+	assert.Equal(s.t, buildURL, s.info.buildURL)
+	return s
+}
+
+func (s *providerVersionStage) the_root_dir_is_the_working_directory() *providerVersionStage {
+	require.NoError(s.t, s.err)
+	cwd, err := os.Getwd()
+	require.NoError(s.t, err)
+	assert.Equal(s.t, cwd, s.rootDir)
+	return s
+}
+
+func (s *providerVersionStage) the_root_dir_is(dir string) *providerVersionStage {
+	require.NoError(s.t, s.err)
+	assert.Equal(s.t, dir, s.rootDir)
+	return s
+}
+
+func (s *providerVersionStage) resolving_fails_with(message string) *providerVersionStage {
+	require.Error(s.t, s.err)
+	assert.Contains(s.t, s.err.Error(), message)
+	return s
+}
+
+func (s *providerVersionStage) the_verification_has(field string, value interface{}) *providerVersionStage {
+	assert.Equal(s.t, value, s.written[field])
+	return s
+}
+
+func fixedVersion(version string) VersionSource {
+	return func() (string, error) {
+		return version, nil
+	}
+}
+
+func failingVersion(message string) VersionSource {
+	return func() (string, error) {
+		return "", errors.New(message)
+	}
+}
+
+func unexpectedVersionSource(t *testing.T) VersionSource {
+	return func() (string, error) {
+		t.Error("version source should not be used")
+		return "", nil
+	}
+}
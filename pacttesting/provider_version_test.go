@@ -0,0 +1,129 @@
+package pacttesting
+
+import "testing"
+
+func TestProviderVersion_ExplicitVersionIsUsed(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		a_provider_version("1.2.3").and().
+		version_sources(unexpectedVersionSource(t))
+
+	when.
+		the_provider_version_is_resolved()
+
+	then.
+		the_version_is("1.2.3")
+}
+
+func TestProviderVersion_FirstSourceWithVersionIsUsed(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		version_sources(
+			failingVersion("fatal: No names found, cannot describe anything."),
+			VersionFromEnv("SERVICE_VERSION"),
+			fixedVersion("a1b2c3d"),
+			unexpectedVersionSource(t),
+		)
+
+	when.
+		the_provider_version_is_resolved()
+
+	then.
+		the_version_is("a1b2c3d")
+}
+
+func TestProviderVersion_EnvironmentVariableSource(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		the_environment_variable("SERVICE_VERSION", "2.0.0").and().
+		version_sources(VersionFromEnv("SERVICE_VERSION"), unexpectedVersionSource(t))
+
+	when.
+		the_provider_version_is_resolved()
+
+	then.
+		the_version_is("2.0.0")
+}
+
+func TestProviderVersion_FailsWithoutVersion(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		version_sources(failingVersion("fatal: No names found"), fixedVersion(""))
+
+	when.
+		the_provider_version_is_resolved()
+
+	then.
+		resolving_fails_with("no provider version found").and().
+		resolving_fails_with("fatal: No names found")
+}
+
+func TestProviderVersion_BranchAndBuildURLFromEnvironment(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		a_provider_version("1.2.3").and().
+		the_environment_variable("PACT_PROVIDER_BRANCH", "main").and().
+		the_environment_variable("GITHUB_SERVER_URL", "https://github.com").and().
+		the_environment_variable("GITHUB_REPOSITORY", "form3tech-oss/go-pact-testing").and().
+		the_environment_variable("GITHUB_RUN_ID", "42")
+
+	when.
+		the_provider_version_is_resolved()
+
+	then.
+		the_branch_and_build_url_are("main", "https://github.com/form3tech-oss/go-pact-testing/actions/runs/42")
+}
+
+func TestProviderVersion_MetadataIsWrittenWithVerification(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		a_provider_version("1.2.3").and().
+		a_branch_and_build_url("feature/x", "https://ci.example.com/builds/7")
+
+	when.
+		the_provider_version_is_resolved().and().
+		the_verification_is_written()
+
+	then.
+		the_verification_has("success", true).and().
+		the_verification_has("providerApplicationVersion", "1.2.3").and().
+		the_verification_has("providerVersionBranch", "feature/x").and().
+		the_verification_has("buildUrl", "https://ci.example.com/builds/7")
+}
+
+func TestProviderVersion_VerificationWorksOutsideGitCheckout(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		the_working_directory_is_not_a_git_checkout().and().
+		the_environment_variable("PACT_PROVIDER_VERSION", "1.2.3")
+
+	when.
+		the_provider_version_is_resolved().and().
+		the_root_dir_is_resolved().and().
+		the_verification_is_written()
+
+	then.
+		the_root_dir_is_the_working_directory().and().
+		the_version_is("1.2.3").and().
+		the_verification_has("providerApplicationVersion", "1.2.3")
+}
+
+func TestProviderVersion_RootDirIsUsedWhenGiven(t *testing.T) {
+	given, when, then := ProviderVersionTest(t)
+
+	given.
+		a_root_dir("/srv/provider")
+
+	when.
+		the_root_dir_is_resolved()
+
+	then.
+		the_root_dir_is("/srv/provider")
+}
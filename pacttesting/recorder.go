@@ -0,0 +1,350 @@
+package pacttesting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// redactedHeaderValue replaces the values of redacted response headers in recorded fixtures.
+const redactedHeaderValue = "REDACTED"
+
+// BodyNormaliser rewrites a recorded JSON body, e.g. to replace generated IDs and timestamps with stable values.
+// It is given the decoded body and returns the body to write.
+type BodyNormaliser func(body interface{}) interface{}
+
+// Recorder is a reverse proxy that records the traffic between a consumer and a real provider into a pact
+// fixture. It is exposed like a mock server: once started, viper key <Provider> and environment variable
+// PACTTESTING_<PROVIDER> point at it, so the code under test talks to the recorder instead of the mock service.
+// Stop writes the recorded interactions to <Dir>/<FileName>, ready to be replayed by AddPact. A request that
+// is sent more than once is recorded with its first response.
+type Recorder struct {
+	Provider string
+	Consumer string
+	// Target is the base URL of the provider that requests are forwarded to.
+	Target string
+	// Dir is the directory the fixture is written to, <cwd>/pacts by default.
+	Dir string
+	// FileName is the name of the fixture, <provider>.recorded.json by default.
+	FileName string
+	// ProviderState, if set, is the provider state of every recorded interaction.
+	ProviderState string
+	// RequestHeaders lists the request headers to record besides Content-Type. Other request headers are not
+	// recorded, since the mock service would require them on replay.
+	RequestHeaders []string
+	// RedactHeaders lists headers that must not be recorded as they are. Redacted request headers are left out,
+	// since the mock service would require their value on replay, and redacted response headers are recorded as
+	// REDACTED. Authorization, Cookie and Set-Cookie are always redacted.
+	RedactHeaders []string
+	// BodyNormalisers are applied in order to every recorded JSON request and response body.
+	BodyNormalisers []BodyNormaliser
+
+	mu           sync.Mutex
+	interactions []interface{}
+	server       *http.Server
+	baseURL      string
+}
+
+// BaseURL returns the URL the recorder listens on, once it has been started.
+func (r *Recorder) BaseURL() string {
+	return r.baseURL
+}
+
+// Start starts the recording proxy and exposes its URL for Provider.
+func (r *Recorder) Start() error {
+	target, err := url.Parse(r.Target)
+	if err != nil {
+		return fmt.Errorf("parsing recorder target '%s': %w", r.Target, err)
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(getBindAddress(), "0"))
+	if err != nil {
+		return fmt.Errorf("starting recorder for %s: %w", r.Provider, err)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		// without Accept-Encoding the transport asks for gzip itself and decompresses the response, so that
+		// bodies are recorded as JSON rather than compressed bytes
+		req.Header.Del("Accept-Encoding")
+	}
+	proxy.ModifyResponse = r.record
+	r.server = &http.Server{Handler: captureRequest(proxy), ReadHeaderTimeout: 10 * time.Second}
+	r.baseURL = providerHTTPScheme + listener.Addr().String()
+	go func() {
+		if err := r.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Errorf("recorder for %s stopped", r.Provider)
+		}
+	}()
+
+	exposeServerURL(r.Provider, r.baseURL)
+	log.Infof("recording %s at %s, forwarding to %s", r.Provider, r.baseURL, r.Target)
+	return nil
+}
+
+// Stop shuts the proxy down and writes the recorded interactions.
+func (r *Recorder) Stop() error {
+	if r.server != nil {
+		if err := r.server.Shutdown(context.Background()); err != nil {
+			return fmt.Errorf("stopping recorder for %s: %w", r.Provider, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.interactions == nil {
+		r.interactions = []interface{}{}
+	}
+	doc := pactDocument{
+		"consumer":     map[string]interface{}{"name": r.Consumer},
+		"provider":     map[string]interface{}{"name": r.Provider},
+		"interactions": r.interactions,
+		"metadata":     map[string]interface{}{"pactSpecification": map[string]interface{}{"version": "3.0.0"}},
+	}
+	data, err := marshalPactDocument(doc)
+	if err == nil {
+		data, err = FormatPact(data)
+	}
+	if err != nil {
+		return fmt.Errorf("encoding recorded pact: %w", err)
+	}
+
+	file := r.fixturePath()
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return fmt.Errorf("creating fixture directory: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("writing recorded pact '%s': %w", file, err)
+	}
+	log.Infof("recorded %d interactions with %s to %s", len(r.interactions), r.Provider, file)
+	return nil
+}
+
+func (r *Recorder) fixturePath() string {
+	dir := r.Dir
+	if dir == "" {
+		cwd, _ := os.Getwd()
+		dir = filepath.Join(cwd, "pacts")
+	}
+	name := r.FileName
+	if name == "" {
+		name = sanitize(r.Provider) + ".recorded.json"
+	}
+	return filepath.Join(dir, name)
+}
+
+// capturedRequest is the request as the consumer sent it, before the proxy rewrote its URL and consumed its body.
+type capturedRequest struct {
+	url  *url.URL
+	body []byte
+}
+
+type capturedRequestKey struct{}
+
+func captureRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, "reading request body: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		captured := &capturedRequest{url: req.URL, body: body}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), capturedRequestKey{}, captured)))
+	})
+}
+
+// record captures a proxied request and its response.
+func (r *Recorder) record(res *http.Response) error {
+	responseBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	req := res.Request
+	captured, ok := req.Context().Value(capturedRequestKey{}).(*capturedRequest)
+	if !ok {
+		return errors.New("recorder received a response to an unknown request")
+	}
+
+	request := map[string]interface{}{"method": req.Method, "path": captured.url.Path}
+	if captured.url.RawQuery != "" {
+		request["query"] = captured.url.RawQuery
+	}
+	recordedRequestHeaders := append([]string{"Content-Type"}, r.RequestHeaders...)
+	if headers := r.recordHeaders(req.Header, func(name string) bool {
+		return containsFold(recordedRequestHeaders, name) && !r.redacted(name)
+	}); len(headers) > 0 {
+		request["headers"] = headers
+	}
+	if body, ok := r.recordBody(captured.body, req.Header.Get("Content-Type")); ok {
+		request["body"] = body
+	}
+
+	response := map[string]interface{}{"status": res.StatusCode}
+	if headers := r.recordHeaders(res.Header, func(name string) bool {
+		return !containsFold(unrecordedResponseHeaders, name)
+	}); len(headers) > 0 {
+		response["headers"] = headers
+	}
+	if body, ok := r.recordBody(responseBody, res.Header.Get("Content-Type")); ok {
+		response["body"] = body
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interaction := map[string]interface{}{"request": request, "response": response}
+	if r.ProviderState != "" {
+		interaction["providerStates"] = []interface{}{map[string]interface{}{"name": r.ProviderState}}
+	}
+	// the mock service cannot tell apart interactions with the same request and provider state, so only the
+	// first response to a request is kept
+	for _, existing := range r.interactions {
+		recorded, _ := existing.(map[string]interface{})
+		if canonicalJSON(recorded["request"]) == canonicalJSON(request) {
+			if canonicalJSON(recorded["response"]) != canonicalJSON(response) {
+				log.Warnf("recorder for %s keeps the first response to %s %s", r.Provider, req.Method, captured.url.Path)
+			}
+			return nil
+		}
+	}
+	interaction["description"] = r.uniqueDescription(req.Method + " " + captured.url.Path)
+	r.interactions = append(r.interactions, interaction)
+	return nil
+}
+
+func (r *Recorder) uniqueDescription(description string) string {
+	used := make(map[string]bool, len(r.interactions))
+	for _, existing := range r.interactions {
+		recorded, _ := existing.(map[string]interface{})
+		name, _ := recorded["description"].(string)
+		used[name] = true
+	}
+	unique := description
+	for n := 2; used[unique]; n++ {
+		unique = description + " #" + strconv.Itoa(n)
+	}
+	return unique
+}
+
+// unrecordedResponseHeaders are response headers that differ between responses or are set by the mock service.
+//
+//nolint:gochecknoglobals // constant list
+var unrecordedResponseHeaders = []string{
+	"Connection", "Content-Length", "Date", "Keep-Alive", "Server", "Transfer-Encoding",
+}
+
+// alwaysRedactedHeaders hold credentials that must never end up in a fixture.
+//
+//nolint:gochecknoglobals // constant list
+var alwaysRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+func (r *Recorder) recordHeaders(header http.Header, include func(name string) bool) map[string]interface{} {
+	headers := make(map[string]interface{})
+	for name, values := range header {
+		if !include(name) {
+			continue
+		}
+		value := strings.Join(values, ", ")
+		if r.redacted(name) {
+			value = redactedHeaderValue
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+func (r *Recorder) redacted(name string) bool {
+	return containsFold(alwaysRedactedHeaders, name) || containsFold(r.RedactHeaders, name)
+}
+
+// recordBody decodes JSON bodies and applies the body normalisers; other bodies are recorded as text.
+func (r *Recorder) recordBody(body []byte, contentType string) (interface{}, bool) {
+	if len(body) == 0 {
+		return nil, false
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return string(body), true
+	}
+	decoded, err := decodeJSON(body)
+	if err != nil {
+		return string(body), true
+	}
+	for _, normalise := range r.BodyNormalisers {
+		decoded = normalise(decoded)
+	}
+	return decoded, true
+}
+
+// ReplaceFields returns a BodyNormaliser that sets every object field with one of the given names, at any
+// depth, to the given value, e.g. ReplaceFields(map[string]interface{}{"created_on": "2020-01-01T00:00:00Z"}).
+func ReplaceFields(values map[string]interface{}) BodyNormaliser {
+	return func(body interface{}) interface{} {
+		return mapFields(body, func(name string, value interface{}) interface{} {
+			if replacement, ok := values[name]; ok {
+				return replacement
+			}
+			return value
+		})
+	}
+}
+
+// LikeFields returns a BodyNormaliser that wraps every object field with one of the given names, at any depth,
+// in {"$like": value} matcher shorthand, so replayed responses and verification only compare their type.
+func LikeFields(names ...string) BodyNormaliser {
+	return func(body interface{}) interface{} {
+		return mapFields(body, func(name string, value interface{}) interface{} {
+			if containsString(names, name) {
+				return map[string]interface{}{shorthandLike: value}
+			}
+			return value
+		})
+	}
+}
+
+// mapFields replaces the value of every object field in v with f(name, value), depth first.
+func mapFields(v interface{}, f func(name string, value interface{}) interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for name, child := range value {
+			result[name] = f(name, mapFields(child, f))
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, child := range value {
+			result[i] = mapFields(child, f)
+		}
+		return result
+	}
+	return v
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
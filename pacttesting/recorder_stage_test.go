@@ -0,0 +1,182 @@
+package pacttesting
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorderStage struct {
+	t        *testing.T
+	provider *httptest.Server
+	recorder *Recorder
+	headers  http.Header
+	dir      string
+	fixture  map[string]interface{}
+}
+
+func RecorderTest(t *testing.T) (*recorderStage, *recorderStage, *recorderStage) {
+	t.Helper()
+	s := &recorderStage{t: t, dir: t.TempDir(), headers: http.Header{}}
+	t.Cleanup(func() {
+		if s.provider != nil {
+			s.provider.Close()
+		}
+	})
+	return s, s, s
+}
+
+func (s *recorderStage) and() *recorderStage {
+	return s
+}
+
+func (s *recorderStage) a_provider_returning_an_organisation() *recorderStage {
+	s.provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Api-Key", "secret")
+		w.Header().Set("Date", time.Now().Format(http.TimeFormat))
+		_, _ = w.Write([]byte(`{"id": "743d5b63", "created_on": "` + time.Now().Format(time.RFC3339Nano) + `", "version": 3}`))
+	}))
+	return s
+}
+
+func (s *recorderStage) a_provider_gzipping_an_organisation() *recorderStage {
+	s.provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			_, _ = w.Write([]byte(`{"id": "743d5b63"}`))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(`{"id": "743d5b63"}`))
+		_ = gz.Close()
+	}))
+	return s
+}
+
+func (s *recorderStage) a_recorder_for_the_provider() *recorderStage {
+	s.recorder = &Recorder{
+		Provider:      "testservicerecorded",
+		Consumer:      "go-pact-testing",
+		Target:        s.provider.URL,
+		Dir:           s.dir,
+		RedactHeaders: []string{"x-api-key"},
+	}
+	return s
+}
+
+func (s *recorderStage) the_recorder_normalises_bodies(normalisers ...BodyNormaliser) *recorderStage {
+	s.recorder.BodyNormalisers = normalisers
+	return s
+}
+
+func (s *recorderStage) the_recorder_records_request_headers(names ...string) *recorderStage {
+	s.recorder.RequestHeaders = names
+	return s
+}
+
+func (s *recorderStage) the_consumer_sends_header(name, value string) *recorderStage {
+	s.headers.Set(name, value)
+	return s
+}
+
+func (s *recorderStage) the_recorder_is_started() *recorderStage {
+	require.NoError(s.t, s.recorder.Start())
+	return s
+}
+
+func (s *recorderStage) the_organisation_is_requested_through(provider string) *recorderStage {
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet,
+		viper.GetString(provider)+"/v1/organisations/743d5b63", nil)
+	require.NoError(s.t, err)
+	req.Header.Set("X-Request-Id", "1")
+	replaceHeaders(req.Header, s.headers)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.t, err)
+	defer res.Body.Close()
+	assert.Equal(s.t, http.StatusOK, res.StatusCode)
+	assert.Equal(s.t, os.Getenv("PACTTESTING_TESTSERVICERECORDED"), s.recorder.BaseURL())
+	return s
+}
+
+func (s *recorderStage) the_recorder_is_stopped() *recorderStage {
+	require.NoError(s.t, s.recorder.Stop())
+	data, err := os.ReadFile(filepath.Join(s.dir, "testservicerecorded.recorded.json"))
+	require.NoError(s.t, err)
+	require.NoError(s.t, json.Unmarshal(data, &s.fixture))
+	return s
+}
+
+func (s *recorderStage) interaction(index int) map[string]interface{} {
+	interactions, _ := s.fixture["interactions"].([]interface{})
+	require.Greater(s.t, len(interactions), index)
+	interaction, _ := interactions[index].(map[string]interface{})
+	return interaction
+}
+
+func (s *recorderStage) recorded(part string) map[string]interface{} {
+	value, _ := s.interaction(0)[part].(map[string]interface{})
+	return value
+}
+
+func (s *recorderStage) recordedHeaders(part string) map[string]interface{} {
+	headers, _ := s.recorded(part)["headers"].(map[string]interface{})
+	return headers
+}
+
+func (s *recorderStage) the_fixture_has_interactions(descriptions ...string) *recorderStage {
+	interactions, _ := s.fixture["interactions"].([]interface{})
+	actual := make([]string, 0, len(interactions))
+	for i := range interactions {
+		description, _ := s.interaction(i)["description"].(string)
+		actual = append(actual, description)
+	}
+	assert.Equal(s.t, descriptions, actual)
+	return s
+}
+
+func (s *recorderStage) the_recorded_request_has_header(name, value string) *recorderStage {
+	assert.Equal(s.t, value, s.recordedHeaders("request")[name])
+	return s
+}
+
+func (s *recorderStage) the_recorded_request_has_no_header(name string) *recorderStage {
+	assert.NotContains(s.t, s.recordedHeaders("request"), name)
+	return s
+}
+
+func (s *recorderStage) the_recorded_response_has_header(name, value string) *recorderStage {
+	assert.Equal(s.t, value, s.recordedHeaders("response")[name])
+	return s
+}
+
+func (s *recorderStage) the_recorded_response_has_no_header(name string) *recorderStage {
+	assert.NotContains(s.t, s.recordedHeaders("response"), name)
+	return s
+}
+
+func (s *recorderStage) the_recorded_response_body_is(expected string) *recorderStage {
+	body, err := json.Marshal(s.recorded("response")["body"])
+	require.NoError(s.t, err)
+	assert.JSONEq(s.t, expected, string(body))
+	return s
+}
+
+func (s *recorderStage) the_fixture_can_be_loaded() *recorderStage {
+	loaded, err := (&PactLoader{Dir: s.dir}).readPactFile("testservicerecorded.recorded")
+	require.NoError(s.t, err)
+	assert.Len(s.t, loaded.Interactions, 1)
+	return s
+}
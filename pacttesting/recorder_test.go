@@ -0,0 +1,104 @@
+package pacttesting
+
+import "testing"
+
+func TestRecorder_TrafficIsWrittenAsFixture(t *testing.T) {
+	given, when, then := RecorderTest(t)
+
+	given.
+		a_provider_returning_an_organisation().and().
+		a_recorder_for_the_provider()
+
+	when.
+		the_recorder_is_started().and().
+		the_organisation_is_requested_through("testservicerecorded").and().
+		the_recorder_is_stopped()
+
+	then.
+		the_fixture_has_interactions("GET /v1/organisations/743d5b63").and().
+		the_recorded_request_has_no_header("X-Request-Id").and().
+		the_recorded_response_has_header("Content-Type", "application/json").and().
+		the_recorded_response_has_header("Set-Cookie", "REDACTED").and().
+		the_recorded_response_has_header("X-Api-Key", "REDACTED").and().
+		the_recorded_response_has_no_header("Date").and().
+		the_fixture_can_be_loaded()
+}
+
+func TestRecorder_BodiesAreNormalised(t *testing.T) {
+	given, when, then := RecorderTest(t)
+
+	given.
+		a_provider_returning_an_organisation().and().
+		a_recorder_for_the_provider().and().
+		the_recorder_normalises_bodies(
+			ReplaceFields(map[string]interface{}{"created_on": "2020-01-01T00:00:00Z"}),
+			LikeFields("version"),
+		)
+
+	when.
+		the_recorder_is_started().and().
+		the_organisation_is_requested_through("testservicerecorded").and().
+		the_recorder_is_stopped()
+
+	then.
+		the_recorded_response_body_is(`{"id": "743d5b63", "created_on": "2020-01-01T00:00:00Z", "version": {"$like": 3}}`).and().
+		the_fixture_can_be_loaded()
+}
+
+func TestRecorder_RepeatedRequestsAreRecordedOnce(t *testing.T) {
+	given, when, then := RecorderTest(t)
+
+	given.
+		a_provider_returning_an_organisation().and().
+		a_recorder_for_the_provider()
+
+	when.
+		the_recorder_is_started().and().
+		the_organisation_is_requested_through("testservicerecorded").and().
+		the_organisation_is_requested_through("testservicerecorded").and().
+		the_recorder_is_stopped()
+
+	then.
+		the_fixture_has_interactions("GET /v1/organisations/743d5b63")
+}
+
+func TestRecorder_RedactedRequestHeadersAreLeftOut(t *testing.T) {
+	given, when, then := RecorderTest(t)
+
+	given.
+		a_provider_returning_an_organisation().and().
+		a_recorder_for_the_provider().and().
+		the_recorder_records_request_headers("Authorization", "X-Api-Key", "X-Request-Id").and().
+		the_consumer_sends_header("Authorization", "Bearer secret").and().
+		the_consumer_sends_header("X-Api-Key", "secret")
+
+	when.
+		the_recorder_is_started().and().
+		the_organisation_is_requested_through("testservicerecorded").and().
+		the_recorder_is_stopped()
+
+	then.
+		the_recorded_request_has_header("X-Request-Id", "1").and().
+		the_recorded_request_has_no_header("Authorization").and().
+		the_recorded_request_has_no_header("X-Api-Key").and().
+		the_fixture_can_be_loaded()
+}
+
+func TestRecorder_CompressedResponsesAreRecordedDecoded(t *testing.T) {
+	given, when, then := RecorderTest(t)
+
+	given.
+		a_provider_gzipping_an_organisation().and().
+		a_recorder_for_the_provider().and().
+		the_consumer_sends_header("Accept-Encoding", "gzip")
+
+	when.
+		the_recorder_is_started().and().
+		the_organisation_is_requested_through("testservicerecorded").and().
+		the_recorder_is_stopped()
+
+	then.
+		the_recorded_response_body_is(`{"id": "743d5b63"}`).and().
+		the_recorded_response_has_no_header("Content-Encoding").and().
+		the_fixture_can_be_loaded()
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -50,40 +51,6 @@ func defaultRetryOptions() []retry.Option {
 	}
 }
 
-func readPactFile(pactFilePath string) *pact {
-	dir, _ := os.Getwd()
-
-	var file string
-	if strings.HasSuffix(pactFilePath, ".json") {
-		file = pactFilePath
-	} else {
-		file = pactFilePath + ".json"
-	}
-	path := filepath.FromSlash(filepath.Join(dir, "pacts", file))
-
-	pactString, err := os.ReadFile(path)
-	if err != nil {
-		panic(err)
-	}
-
-	p := &pact{}
-	err = json.Unmarshal(pactString, p)
-	if err != nil {
-		panic(err)
-	}
-
-	return p
-}
-
-func readAllPacts(pacts []string) []*pact {
-	results := make([]*pact, len(pacts))
-	for i, p := range pacts {
-		results[i] = readPactFile(p)
-	}
-
-	return results
-}
-
 func groupByProvider(pacts []*pact) []*pact {
 	pactMap := make(map[string]*pact)
 
@@ -112,31 +79,29 @@ func groupByProvider(pacts []*pact) []*pact {
 }
 
 func getTopLevelDir() (string, error) {
-	gitCommand := exec.Command("git", "rev-parse", "--show-toplevel")
-	var out bytes.Buffer
-	gitCommand.Stdout = &out
-	err := gitCommand.Run()
+	topLevelDir, err := gitOutput("rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", fmt.Errorf("getting git top level dir: %w", err)
 	}
-
-	topLevelDir := strings.TrimRight(out.String(), "\n")
 	return topLevelDir, nil
 }
 
-func getVersion() (string, error) {
-	gitCommand := exec.Command("git", "describe", "--tags")
-	var out bytes.Buffer
-	var errOut bytes.Buffer
-	gitCommand.Stdout = &out
-	gitCommand.Stderr = &errOut
-	err := gitCommand.Run()
+// providerRootDir returns the directory the paths of params are relative to: params.RootDir, the top level
+// directory of the git checkout, or the working directory outside a git checkout.
+func providerRootDir(params PactProviderTestParams) (string, error) {
+	if params.RootDir != "" {
+		return params.RootDir, nil
+	}
+	topLevelDir, err := getTopLevelDir()
+	if err == nil {
+		return topLevelDir, nil
+	}
+	log.WithError(err).Warn("not in a git checkout, resolving pact paths against the working directory")
+	dir, err := os.Getwd()
 	if err != nil {
-		return "", fmt.Errorf("getting git version: %w; out: %s", err, errOut.String())
+		return "", fmt.Errorf("getting working directory: %w", err)
 	}
-
-	version := strings.TrimRight(out.String(), "\n")
-	return version, nil
+	return dir, nil
 }
 
 func setBinPath() {
@@ -171,7 +136,15 @@ func buildPactClientOnce() {
 // This is necessary to get viper configuration before actually loading pact files.
 // This function can be called multiple times for the same files, it will only initialise them once.
 func PreassignPorts(pactFilePaths []Pact) {
-	pacts := groupByProvider(readAllPacts(pactFilePaths))
+	(&PactLoader{}).PreassignPorts(pactFilePaths)
+}
+
+// PreassignPorts is the PactLoader variant of the package level PreassignPorts.
+func (l *PactLoader) PreassignPorts(pactFilePaths []Pact) {
+	preassignPorts(groupByProvider(l.mustReadAllPacts(pactFilePaths)))
+}
+
+func preassignPorts(pacts []*pact) {
 	for _, p := range pacts {
 		mockServer := loadRunningServer(p.Provider.Name, p.Consumer.Name)
 		if mockServer == nil {
@@ -214,6 +187,7 @@ func ResetPacts() {
 		if !pactServer.Running {
 			continue
 		}
+		collectCoverage(pactServer)
 		err := pactServer.DeleteInteractions()
 		if err != nil {
 			log.WithError(err).Errorf("unable to delete configured interactions for %s", key)
@@ -224,13 +198,22 @@ func ResetPacts() {
 // TestWithStubServices runs testFunc with stub services defined by given pacts.
 // Does not verify that the stubs are called
 func TestWithStubServices(pactFilePaths []Pact, testFunc func()) error {
-	defer ResetPacts()
+	return (&PactLoader{}).TestWithStubServices(pactFilePaths, testFunc)
+}
 
-	PreassignPorts(pactFilePaths)
+// TestWithStubServices is the PactLoader variant of the package level TestWithStubServices.
+// It panics if the pact files cannot be loaded, e.g. because of unresolved variables.
+func (l *PactLoader) TestWithStubServices(pactFilePaths []Pact, testFunc func()) error {
+	return testWithStubServices(groupByProvider(l.mustReadAllPacts(pactFilePaths)), testFunc)
+}
+
+func testWithStubServices(pacts []*pact, testFunc func()) error {
+	defer ResetPacts()
 
-	pacts := groupByProvider(readAllPacts(pactFilePaths))
+	preassignPorts(pacts)
 
 	for _, server := range pactServers {
+		collectCoverage(server)
 		err := server.DeleteInteractions()
 		if err != nil {
 			log.WithError(err).Errorf("Error deleting interactions")
@@ -246,7 +229,9 @@ func TestWithStubServices(pactFilePaths []Pact, testFunc func()) error {
 			err = pactServers[key].AddInteraction(i)
 			if err != nil {
 				log.Errorf("Error adding pact: %v", err)
+				continue
 			}
+			trackRegistered(pactServers[key], i)
 		}
 	}
 
@@ -256,8 +241,17 @@ func TestWithStubServices(pactFilePaths []Pact, testFunc func()) error {
 
 // AddPact loads a pact definition from a file and ensures that stub servers are running.
 func AddPact(filename string) error {
+	return (&PactLoader{}).AddPact(filename)
+}
+
+// AddPact is the PactLoader variant of the package level AddPact.
+func (l *PactLoader) AddPact(filename string) error {
 	pactFilePaths := []string{filename}
-	pacts := groupByProvider(readAllPacts(pactFilePaths))
+	loaded, err := l.readAllPacts(pactFilePaths)
+	if err != nil {
+		return fmt.Errorf("error loading pact from %s: %w", filename, err)
+	}
+	pacts := groupByProvider(loaded)
 	for _, p := range pacts {
 		key := p.Provider.Name + p.Consumer.Name
 		EnsurePactRunning(p.Provider.Name, p.Consumer.Name)
@@ -267,11 +261,18 @@ func AddPact(filename string) error {
 			if err != nil {
 				return fmt.Errorf("error adding pact from %s: %w", filename, err)
 			}
+			trackRegistered(pactServers[key], i)
 		}
 	}
 	return nil
 }
 
+// AddPactSelecting is AddPact for the interactions of filename that any of the selectors select,
+// so a test can stub a few endpoints out of a shared per-provider pact file.
+func AddPactSelecting(filename string, selectors ...InteractionSelector) error {
+	return (&PactLoader{Selectors: selectors}).AddPact(filename)
+}
+
 // AddPactInteraction ensures that a stub server is running for the provided provider/consumer and returns an
 // interaction to be configured
 func AddPactInteraction(provider, consumer string, interaction *dsl.Interaction) error {
@@ -401,7 +402,19 @@ func EnsurePactRunning(provider, consumer string) string {
 // invokes testFunc then verifies that the pacts have been invoked successfully
 func RunIntegrationTest(t *testing.T, pactFilePaths []Pact, testFunc func(), retryOptions ...retry.Option) error {
 	t.Helper()
-	return TestWithStubServices(pactFilePaths, func() {
+	return (&PactLoader{}).RunIntegrationTest(t, pactFilePaths, testFunc, retryOptions...)
+}
+
+// RunIntegrationTest is the PactLoader variant of the package level RunIntegrationTest.
+func (l *PactLoader) RunIntegrationTest(
+	t *testing.T,
+	pactFilePaths []Pact,
+	testFunc func(),
+	retryOptions ...retry.Option,
+) error {
+	t.Helper()
+	pacts := groupByProvider(l.mustReadAllPacts(pactFilePaths))
+	return testWithStubServices(pacts, func() {
 		testFunc()
 
 		// (Re-)try verification according to the specified options (if any).
@@ -410,19 +423,38 @@ func RunIntegrationTest(t *testing.T, pactFilePaths []Pact, testFunc func(), ret
 		if len(retryOptions) == 0 {
 			retryOptions = defaultRetryOptions()
 		}
-		verify := func() error { return checkVerificationStatus(pactFilePaths) }
+		verify := func() error { return checkVerificationStatus(pacts) }
 		if err := retry.Do(verify, retryOptions...); err != nil {
 			log.Error("Pact verification failed!!" +
 				"For more info on the error check the logs/pact*.log files, they are quite detailed")
 			t.Errorf(err.Error())
+			return
 		}
+		formatVerifiedPacts(pacts)
 	})
 }
 
 // Runs mock services defined by the given pacts,
 // invokes testFunc then verifies that the pacts have been invoked successfully
 func IntegrationTest(pactFilePaths []Pact, testFunc func(), retryOptions ...retry.Option) error {
-	return TestWithStubServices(pactFilePaths, func() {
+	return (&PactLoader{}).IntegrationTest(pactFilePaths, testFunc, retryOptions...)
+}
+
+// IntegrationTestSelecting is IntegrationTest for the interactions of the pact files that any of the selectors select.
+// Every pact file must contribute at least one interaction.
+func IntegrationTestSelecting(
+	pactFilePaths []Pact,
+	selectors []InteractionSelector,
+	testFunc func(),
+	retryOptions ...retry.Option,
+) error {
+	return (&PactLoader{Selectors: selectors}).IntegrationTest(pactFilePaths, testFunc, retryOptions...)
+}
+
+// IntegrationTest is the PactLoader variant of the package level IntegrationTest.
+func (l *PactLoader) IntegrationTest(pactFilePaths []Pact, testFunc func(), retryOptions ...retry.Option) error {
+	pacts := groupByProvider(l.mustReadAllPacts(pactFilePaths))
+	return testWithStubServices(pacts, func() {
 		testFunc()
 
 		// (Re-)try verification according to the specified options (if any).
@@ -431,16 +463,16 @@ func IntegrationTest(pactFilePaths []Pact, testFunc func(), retryOptions ...retr
 		if len(retryOptions) == 0 {
 			retryOptions = defaultRetryOptions()
 		}
-		verify := func() error { return checkVerificationStatus(pactFilePaths) }
+		verify := func() error { return checkVerificationStatus(pacts) }
 		if err := retry.Do(verify, retryOptions...); err != nil {
 			log.Fatalf("Pact verification failed!!" +
 				"For more info on the error check the logs/pact*.log files, they are quite detailed")
 		}
+		formatVerifiedPacts(pacts)
 	})
 }
 
-func checkVerificationStatus(pactFilePaths []Pact) error {
-	pacts := groupByProvider(readAllPacts(pactFilePaths))
+func checkVerificationStatus(pacts []*pact) error {
 	for _, p := range pacts { // verify only pacts defined for this TC
 		key := p.Provider.Name + p.Consumer.Name
 		err := pactServers[key].Verify()
@@ -454,11 +486,13 @@ func checkVerificationStatus(pactFilePaths []Pact) error {
 
 func StopMockServers() {
 	for key, s := range pactServers {
+		collectCoverage(s)
 		err := s.Stop()
 		if err != nil {
 			log.WithError(err).Errorf("failed to stop server for consumer(%s), provider(%s)", s.Consumer, s.Provider)
 		} else {
 			delete(pactServers, key)
+			formatWrittenPact(s.Consumer, s.Provider)
 		}
 	}
 }
@@ -476,26 +510,74 @@ func VerifyAll() error {
 }
 
 type PactProviderTestParams struct {
-	Pacts                 string
-	AuthToken             string
-	BaseURL               string
+	Pacts string
+	// RootDir is the directory that Pacts, PendingPacts and the report paths are relative to, and that
+	// build/pact-verifications is written to. It defaults to the top level directory of the git checkout, or the
+	// working directory when there is none, e.g. in a tarball build.
+	RootDir string
+	// PendingPacts names a file listing the pact files that are pending, one file name pattern per line, e.g. a new
+	// consumer's pact the provider does not satisfy yet. Failures of pending pacts are logged as warnings instead of
+	// failing the test. Pacts are also pending when their metadata marks them "pending" or "wip", as
+	// broker.FetchPacts does for the pacts the broker reports as pending or work in progress.
+	PendingPacts string
+	// ProviderVersion is the provider version recorded with the verification results. If empty, it is taken from
+	// the first of VersionSources that has one.
+	ProviderVersion string
+	// VersionSources are tried in order when ProviderVersion is empty. By default these are the
+	// PACT_PROVIDER_VERSION environment variable, git describe --tags, the git commit SHA and the build info of the
+	// running binary.
+	VersionSources []VersionSource
+	// ProviderBranch is recorded with the verification results, by default from PACT_PROVIDER_BRANCH or the checked
+	// out git branch.
+	ProviderBranch string
+	// BuildURL links the verification results to the CI build, by default from PACT_BUILD_URL or the variables of
+	// common CI servers.
+	BuildURL string
+	// AuthToken, if set, is sent as a bearer token in the Authorization header of every verified request.
+	AuthToken string
+	// Headers are added to every verified request. An Authorization header takes precedence over AuthToken.
+	Headers http.Header
+	// ConsumerHeaders maps consumer names, as read from each pact file, to headers added to the requests verifying
+	// that consumer's pacts. They take precedence over Headers.
+	ConsumerHeaders map[string]http.Header
+	// InteractionHeaders add headers to the requests of individual interactions, taking precedence over
+	// ConsumerHeaders.
+	InteractionHeaders []InteractionHeaders
+	BaseURL            string
+	// Handler, if set, is verified in-process instead of the provider at BaseURL: it is served by an httptest.Server
+	// for the duration of the verification. InteractionHeaders and RequestFilter wrap the handler, so they can check
+	// or change responses as well as requests.
+	Handler               http.Handler
 	ProviderStateSetupURL string
-	Testing               *testing.T
+	// StateHandlers set up provider states by name, with the params of v3 provider states. When given, the library
+	// serves the provider state setup endpoint itself and ProviderStateSetupURL must be empty.
+	StateHandlers dsl.StateHandlers
+	// StateTeardownHandlers, if set, remove provider states by name once their interaction has been verified.
+	StateTeardownHandlers dsl.StateHandlers
+	// RequestFilter, if set, is applied to every verified request before it reaches the provider.
+	RequestFilter RequestFilter
+	// JUnitReport, if set, is the path of a JUnit XML report with a test case per verified interaction.
+	JUnitReport string
+	// SummaryReport, if set, is the path of a JSON summary of the verification of all pact files.
+	SummaryReport string
+	Testing       *testing.T
 }
 
-func VerifyProviderPacts(params PactProviderTestParams) {
+// VerifyProviderPacts verifies the provider against every pact file matching params.Pacts, as a subtest per file,
+// and returns the result for each file.
+func VerifyProviderPacts(params PactProviderTestParams) []*VerificationResult {
 	buildPactClientOnce()
 
-	version, err := getVersion()
+	providerVersion, err := resolveProviderVersion(params)
 	if err != nil {
 		params.Testing.Error(err)
-		return
+		return nil
 	}
 
-	topLevelDir, err := getTopLevelDir()
+	topLevelDir, err := providerRootDir(params)
 	if err != nil {
 		params.Testing.Error(err)
-		return
+		return nil
 	}
 
 	var pactsFilter string
@@ -513,61 +595,112 @@ func VerifyProviderPacts(params PactProviderTestParams) {
 		params.Testing.Error("No pacts found")
 	}
 
+	var results []*VerificationResult
 	for _, url := range urls {
 		urlparts := strings.SplitAfter(url, "/")
 		filename := urlparts[len(urlparts)-1]
 		params.Testing.Run(filename, func(t *testing.T) {
+			target, err := newProviderTarget(params, url)
+			if err != nil {
+				results = append(results, newVerificationResult(url, time.Now(), nil, err))
+				t.Fatal(err)
+			}
+			defer target.close()
+
 			request := types.VerifyRequest{
-				ProviderBaseURL:        params.BaseURL,
+				ProviderBaseURL:        target.baseURL,
 				PactURLs:               []string{url},
-				CustomProviderHeaders:  []string{"Authorization: Bearer " + params.AuthToken},
-				ProviderVersion:        version,
-				ProviderStatesSetupURL: params.ProviderStateSetupURL,
+				CustomProviderHeaders:  target.headers,
+				ProviderVersion:        providerVersion.version,
+				ProviderStatesSetupURL: target.stateSetupURL,
 			}
 
+			started := time.Now()
 			responses, verifyErr := pactClient.VerifyProvider(request)
-			allTestsSucceeded := true
+			result := newVerificationResult(url, started, responses, verifyErr)
+			results = append(results, result)
+			if err := markPending(params, topLevelDir, result); err != nil {
+				t.Error(err)
+			}
 
 			for _, response := range responses {
 				for _, example := range response.Examples {
-					if !t.Run(example.Description, func(st *testing.T) {
-						if example.Status != "passed" {
-							st.Errorf("%s\n%s\n", example.FullDescription, example.Exception.Message)
-						} else {
+					t.Run(example.Description, func(st *testing.T) {
+						switch {
+						case example.Status == "passed":
 							st.Log(example.FullDescription)
+						case result.Pending:
+							st.Logf("WARNING: pending pact failed, not failing the test\n%s\n%s\n",
+								example.FullDescription, example.Exception.Message)
+						default:
+							st.Errorf("%s\n%s\n", example.FullDescription, example.Exception.Message)
 						}
-					}) {
-						allTestsSucceeded = false
-					}
+					})
 				}
-
-				t.Run("==> Writing verification.json", func(t *testing.T) {
-					verificationJSON := fmt.Sprintf("{\"success\": %v,\"providerApplicationVersion\": \"%s\"}",
-						allTestsSucceeded,
-						version)
-					verificationDir := filepath.Join(topLevelDir, "build", "pact-verifications")
-					_ = os.MkdirAll(verificationDir+"/", 0o744)
-					verificationFile := filepath.Join(verificationDir, filename)
-					if err := os.WriteFile(verificationFile, []byte(verificationJSON), 0o600); err != nil {
-						t.Fatal(err)
-					}
-					outputJSON, err := json.Marshal(response)
-					if err != nil {
-						t.Fatal(err)
-					}
-
-					outFile := filepath.Join(topLevelDir, "build/pact-verifications/", "output-"+filename)
-					if err := os.WriteFile(outFile, outputJSON, 0o600); err != nil {
-						t.Fatal(err)
-					}
-				})
 			}
+			logPendingResult(t, result)
 
-			if verifyErr != nil {
+			t.Run("==> Writing verification.json", func(t *testing.T) {
+				if err := writeVerification(topLevelDir, filename, providerVersion, result, responses); err != nil {
+					t.Fatal(err)
+				}
+			})
+
+			if verifyErr != nil && result.blocking() {
 				t.Fatal(verifyErr)
 			}
 		})
 	}
+
+	if err := writeVerificationReports(params, topLevelDir, providerVersion, results); err != nil {
+		params.Testing.Error(err)
+	}
+	return results
+}
+
+// verificationJSON is the verification result written to build/pact-verifications, in the form the pact broker
+// accepts. The broker keeps testResults as the details of the verification.
+type verificationJSON struct {
+	Success                    bool                `json:"success"`
+	ProviderApplicationVersion string              `json:"providerApplicationVersion"`
+	ProviderVersionBranch      string              `json:"providerVersionBranch,omitempty"`
+	BuildURL                   string              `json:"buildUrl,omitempty"`
+	TestResults                *VerificationResult `json:"testResults,omitempty"`
+}
+
+// writeVerification writes the verification result of a pact file and the combined verifier output to
+// build/pact-verifications.
+func writeVerification(
+	topLevelDir, filename string,
+	providerVersion providerVersionInfo,
+	result *VerificationResult,
+	responses []types.ProviderVerifierResponse,
+) error {
+	verificationDir := filepath.Join(topLevelDir, "build", "pact-verifications")
+	_ = os.MkdirAll(verificationDir+"/", 0o744)
+
+	verification, err := json.Marshal(verificationJSON{
+		Success:                    result.Success,
+		ProviderApplicationVersion: providerVersion.version,
+		ProviderVersionBranch:      providerVersion.branch,
+		BuildURL:                   providerVersion.buildURL,
+		TestResults:                result,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding verification result: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(verificationDir, filename), verification, 0o600); err != nil {
+		return fmt.Errorf("writing verification result: %w", err)
+	}
+
+	outputJSON, err := json.Marshal(mergeVerifierResponses(responses))
+	if err != nil {
+		return fmt.Errorf("encoding verifier output: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(verificationDir, "output-"+filename), outputJSON, 0o600); err != nil {
+		return fmt.Errorf("writing verifier output: %w", err)
+	}
+	return nil
 }
 
 func getBindAddress() string {
@@ -0,0 +1,357 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+)
+
+// Interaction verification statuses, as reported by the verifier.
+const (
+	InteractionPassed  = "passed"
+	InteractionFailed  = "failed"
+	InteractionPending = "pending"
+)
+
+// VerificationSummary is the outcome of verifying a provider against all pact files. Its JSON form is stable, so
+// that CI tooling can rely on it.
+type VerificationSummary struct {
+	ProviderVersion string                `json:"providerVersion"`
+	ProviderBranch  string                `json:"providerBranch,omitempty"`
+	BuildURL        string                `json:"buildUrl,omitempty"`
+	Success         bool                  `json:"success"`
+	Interactions    int                   `json:"interactions"`
+	Failures        int                   `json:"failures"`
+	Pacts           []*VerificationResult `json:"pacts"`
+}
+
+// VerificationResult is the outcome of verifying a provider against one pact file, aggregated over all responses of
+// the verifier.
+type VerificationResult struct {
+	File     string `json:"file"`
+	Consumer string `json:"consumer"`
+	// ConsumerVersion is the consumer version the pact was published with, for pacts fetched from a pact broker.
+	ConsumerVersion string `json:"consumerVersion,omitempty"`
+	Provider        string `json:"provider"`
+	Success         bool   `json:"success"`
+	// Pending pacts have not been verified by a provider version yet, so their failures are warnings rather than
+	// test failures. WIP pacts are work in progress pacts, which are always pending.
+	Pending bool `json:"pending,omitempty"`
+	WIP     bool `json:"wip,omitempty"`
+	// pendingLocally is set when the pact is pending because of the PendingPacts allowlist or a marker in a pact
+	// that was not fetched from a pact broker, which nothing clears once the pact passes.
+	pendingLocally bool
+	// Error is set when the verification itself failed, e.g. because the verifier could not be run.
+	Error        string              `json:"error,omitempty"`
+	StartedAt    time.Time           `json:"startedAt"`
+	FinishedAt   time.Time           `json:"finishedAt"`
+	Interactions []InteractionResult `json:"interactions"`
+}
+
+// InteractionResult is the outcome of verifying a single interaction.
+type InteractionResult struct {
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	// Message explains a failure, including the differences the verifier found.
+	Message string `json:"message,omitempty"`
+	// Duration is the time the verifier took for the interaction, in seconds.
+	Duration float64 `json:"duration"`
+}
+
+// newVerificationResult aggregates all verifier responses for the pact file, whose verification started at started.
+func newVerificationResult(
+	file string,
+	started time.Time,
+	responses []types.ProviderVerifierResponse,
+	verifyErr error,
+) *VerificationResult {
+	result := &VerificationResult{
+		File:         file,
+		StartedAt:    started.UTC(),
+		FinishedAt:   time.Now().UTC(),
+		Interactions: []InteractionResult{},
+	}
+	if doc, err := readPactDocument(file); err == nil {
+		result.Consumer = participantName(doc, "consumer")
+		result.ConsumerVersion = consumerVersion(doc)
+		result.Provider = participantName(doc, "provider")
+	}
+	if verifyErr != nil {
+		result.Error = verifyErr.Error()
+	}
+
+	for _, response := range responses {
+		for _, example := range response.Examples {
+			if result.Consumer == "" {
+				result.Consumer = example.Pact.ConsumerName
+				result.Provider = example.Pact.ProviderName
+			}
+			interaction := InteractionResult{
+				Description: example.Description,
+				Status:      example.Status,
+				Duration:    example.RunTime,
+			}
+			switch example.Status {
+			case InteractionPassed:
+			case InteractionPending:
+				if example.PendingMessage != nil {
+					interaction.Message = fmt.Sprint(example.PendingMessage)
+				}
+			default:
+				interaction.Message = example.Exception.Message
+				if interaction.Message == "" {
+					interaction.Message = strings.Join(example.Mismatches, "\n")
+				}
+			}
+			result.Interactions = append(result.Interactions, interaction)
+		}
+	}
+
+	result.Success = verifyErr == nil && result.failures() == 0
+	return result
+}
+
+// consumerVersion returns the consumer version in the links of a pact fetched from a pact broker.
+func consumerVersion(doc pactDocument) string {
+	links, _ := doc["_links"].(map[string]interface{})
+	if version, ok := links["pb:consumer-version"].(map[string]interface{}); ok {
+		name, _ := version["name"].(string)
+		return name
+	}
+	versions, _ := links["pb:consumer-versions"].([]interface{})
+	for _, v := range versions {
+		if version, ok := v.(map[string]interface{}); ok {
+			if name, _ := version["name"].(string); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// mergeVerifierResponses combines the responses the verifier gave for a pact file into one.
+func mergeVerifierResponses(responses []types.ProviderVerifierResponse) types.ProviderVerifierResponse {
+	var merged types.ProviderVerifierResponse
+	var summaryLines []string
+	for _, response := range responses {
+		if merged.Version == "" {
+			merged.Version = response.Version
+		}
+		merged.Examples = append(merged.Examples, response.Examples...)
+		merged.Summary.Duration += response.Summary.Duration
+		merged.Summary.ExampleCount += response.Summary.ExampleCount
+		merged.Summary.FailureCount += response.Summary.FailureCount
+		merged.Summary.PendingCount += response.Summary.PendingCount
+		merged.Summary.ErrorsOutsideOfExamplesCount += response.Summary.ErrorsOutsideOfExamplesCount
+		merged.Summary.Notices = append(merged.Summary.Notices, response.Summary.Notices...)
+		if response.SummaryLine != "" {
+			summaryLines = append(summaryLines, response.SummaryLine)
+		}
+	}
+	merged.SummaryLine = strings.Join(summaryLines, "\n")
+	return merged
+}
+
+// blocking reports whether the result fails the verification. Failures of pending pacts do not, unless the pact
+// could not be verified at all.
+func (r *VerificationResult) blocking() bool {
+	if r.Success {
+		return false
+	}
+	return !r.Pending || r.failures() == 0
+}
+
+func (r *VerificationResult) failures() int {
+	failures := 0
+	for _, interaction := range r.Interactions {
+		if interaction.Status != InteractionPassed && interaction.Status != InteractionPending {
+			failures++
+		}
+	}
+	return failures
+}
+
+func newVerificationSummary(providerVersion providerVersionInfo, results []*VerificationResult) *VerificationSummary {
+	summary := &VerificationSummary{
+		ProviderVersion: providerVersion.version,
+		ProviderBranch:  providerVersion.branch,
+		BuildURL:        providerVersion.buildURL,
+		Success:         true,
+		Pacts:           results,
+	}
+	if summary.Pacts == nil {
+		summary.Pacts = []*VerificationResult{}
+	}
+	for _, result := range results {
+		summary.Success = summary.Success && !result.blocking()
+		summary.Interactions += len(result.Interactions)
+		if !result.Pending {
+			summary.Failures += result.failures()
+		}
+	}
+	return summary
+}
+
+// WriteJSON writes the summary as indented JSON to path, creating its directory.
+func (s *VerificationSummary) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding verification summary: %w", err)
+	}
+	return writeReport(path, append(data, '\n'))
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Time       string          `xml:"time,attr,omitempty"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitFailure `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the summary as a JUnit XML report to path, creating its directory. Each pact file is a test
+// suite with a test case per interaction; a verification that failed outright is reported as an error. Failures of
+// pending pacts are reported as skipped.
+func (s *VerificationSummary) WriteJUnit(path string) error {
+	report := junitTestSuites{Name: "pact verification"}
+	for _, result := range s.Pacts {
+		suite := junitTestSuite{
+			Name:      filepath.Base(result.File),
+			Timestamp: result.StartedAt.Format("2006-01-02T15:04:05"),
+			Time:      strconv.FormatFloat(result.FinishedAt.Sub(result.StartedAt).Seconds(), 'f', 3, 64),
+			Properties: []junitProperty{
+				{Name: "consumer", Value: result.Consumer},
+				{Name: "consumerVersion", Value: result.ConsumerVersion},
+				{Name: "provider", Value: result.Provider},
+				{Name: "providerVersion", Value: s.ProviderVersion},
+			},
+		}
+		if result.Pending {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "pending", Value: strconv.FormatBool(true)})
+		}
+		className := result.Consumer + "." + result.Provider
+		for _, interaction := range result.Interactions {
+			testCase := junitTestCase{
+				ClassName: className,
+				Name:      interaction.Description,
+				Time:      strconv.FormatFloat(interaction.Duration, 'f', 3, 64),
+			}
+			switch interaction.Status {
+			case InteractionPassed:
+			case InteractionPending:
+				testCase.Skipped = &junitFailure{Message: "pending", Text: interaction.Message}
+				suite.Skipped++
+			default:
+				if result.Pending {
+					testCase.Skipped = &junitFailure{Message: "pending: " + firstLine(interaction.Message), Text: interaction.Message}
+					suite.Skipped++
+					break
+				}
+				testCase.Failure = &junitFailure{Message: firstLine(interaction.Message), Text: interaction.Message}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		if result.Error != "" && result.blocking() {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				ClassName: className,
+				Name:      "verification",
+				Error:     &junitFailure{Message: firstLine(result.Error), Text: result.Error},
+			})
+			suite.Errors++
+		}
+		suite.Tests = len(suite.Cases)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Suites = append(report.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding JUnit report: %w", err)
+	}
+	return writeReport(path, append([]byte(xml.Header), append(data, '\n')...))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+func writeReport(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("creating report directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing report '%s': %w", path, err)
+	}
+	return nil
+}
+
+// writeVerificationReports writes the reports params asks for, resolving relative paths against topLevelDir.
+func writeVerificationReports(
+	params PactProviderTestParams,
+	topLevelDir string,
+	providerVersion providerVersionInfo,
+	results []*VerificationResult,
+) error {
+	summary := newVerificationSummary(providerVersion, results)
+	resolve := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(topLevelDir, path)
+	}
+	if params.JUnitReport != "" {
+		if err := summary.WriteJUnit(resolve(params.JUnitReport)); err != nil {
+			return err
+		}
+	}
+	if params.SummaryReport != "" {
+		if err := summary.WriteJSON(resolve(params.SummaryReport)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
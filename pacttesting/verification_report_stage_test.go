@@ -0,0 +1,212 @@
+package pacttesting
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pact-foundation/pact-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const verifierResponses = `[
+  {"examples": [
+    {"description": "get an organisation as an admin", "status": "passed",
+     "run_time": 0.25, "pact": {"consumer_name": "consumera", "provider_name": "testservicea"}}
+  ], "summary": {"example_count": 1}},
+  {"examples": [
+    {"description": "get an organisation as a reader", "status": "failed",
+     "pact": {"consumer_name": "consumera", "provider_name": "testservicea"},
+     "exception": {"message": "Diff\n--------------------------------------\nKey: - is expected \n     + is actual \n-  \"status\": 200\n+  \"status\": 403"}}
+  ], "summary": {"example_count": 1, "failure_count": 1}}
+]`
+
+type verificationReportStage struct {
+	t         *testing.T
+	dir       string
+	pactFile  string
+	responses []types.ProviderVerifierResponse
+	verifyErr error
+	results   []*VerificationResult
+	junit     junitTestSuites
+	summary   map[string]interface{}
+	written   map[string]interface{}
+	output    map[string]interface{}
+}
+
+func VerificationReportTest(t *testing.T) (*verificationReportStage, *verificationReportStage, *verificationReportStage) {
+	t.Helper()
+	s := &verificationReportStage{t: t, dir: t.TempDir()}
+	s.pactFile = filepath.Join(s.dir, "testservicea.json")
+	require.NoError(t, os.WriteFile(s.pactFile, []byte(providerRequestPact), 0o600))
+	return s, s, s
+}
+
+func (s *verificationReportStage) and() *verificationReportStage {
+	return s
+}
+
+func (s *verificationReportStage) verifier_responses_with_a_failure() *verificationReportStage {
+	require.NoError(s.t, json.Unmarshal([]byte(verifierResponses), &s.responses))
+	return s
+}
+
+func (s *verificationReportStage) the_verifier_failed_to_run() *verificationReportStage {
+	s.verifyErr = errors.New("pact-provider-verifier: exit status 1")
+	return s
+}
+
+func (s *verificationReportStage) the_results_are_collected() *verificationReportStage {
+	s.results = append(s.results, newVerificationResult(s.pactFile, time.Now(), s.responses, s.verifyErr))
+	return s
+}
+
+func (s *verificationReportStage) the_reports_are_written() *verificationReportStage {
+	params := PactProviderTestParams{JUnitReport: "reports/pact.xml", SummaryReport: filepath.Join(s.dir, "summary.json")}
+	require.NoError(s.t, writeVerificationReports(params, s.dir, providerVersionInfo{version: "1.2.3"}, s.results))
+
+	data, err := os.ReadFile(filepath.Join(s.dir, "reports", "pact.xml"))
+	require.NoError(s.t, err)
+	require.NoError(s.t, xml.Unmarshal(data, &s.junit))
+
+	data, err = os.ReadFile(filepath.Join(s.dir, "summary.json"))
+	require.NoError(s.t, err)
+	require.NoError(s.t, json.Unmarshal(data, &s.summary))
+	return s
+}
+
+func (s *verificationReportStage) the_junit_report_has_cases(tests, failures, errors int) *verificationReportStage {
+	assert.Equal(s.t, tests, s.junit.Tests)
+	assert.Equal(s.t, failures, s.junit.Failures)
+	assert.Equal(s.t, errors, s.junit.Errors)
+	return s
+}
+
+func (s *verificationReportStage) the_junit_case_failed_with(name, message, text string) *verificationReportStage {
+	require.Len(s.t, s.junit.Suites, 1)
+	for _, testCase := range s.junit.Suites[0].Cases {
+		if testCase.Name == name {
+			assert.Equal(s.t, "consumera.testservicea", testCase.ClassName)
+			require.NotNil(s.t, testCase.Failure)
+			assert.Equal(s.t, message, testCase.Failure.Message)
+			assert.Contains(s.t, testCase.Failure.Text, text)
+			return s
+		}
+	}
+	s.t.Errorf("no test case %q", name)
+	return s
+}
+
+func (s *verificationReportStage) the_junit_report_has_a_verification_error() *verificationReportStage {
+	require.Len(s.t, s.junit.Suites, 1)
+	cases := s.junit.Suites[0].Cases
+	require.NotEmpty(s.t, cases)
+	last := cases[len(cases)-1]
+	assert.Equal(s.t, "verification", last.Name)
+	require.NotNil(s.t, last.Error)
+	assert.Equal(s.t, "pact-provider-verifier: exit status 1", last.Error.Message)
+	return s
+}
+
+func (s *verificationReportStage) the_summary_has(field string, value interface{}) *verificationReportStage {
+	assert.Equal(s.t, value, s.summary[field])
+	return s
+}
+
+func (s *verificationReportStage) the_summarised_pact_has(field string, value interface{}) *verificationReportStage {
+	pacts, _ := s.summary["pacts"].([]interface{})
+	require.Len(s.t, pacts, 1)
+	pact, _ := pacts[0].(map[string]interface{})
+	assert.Equal(s.t, value, pact[field])
+	return s
+}
+
+func (s *verificationReportStage) the_pact_was_fetched_from_a_broker_for_consumer_version(version string) *verificationReportStage {
+	doc, err := readPactDocument(s.pactFile)
+	require.NoError(s.t, err)
+	doc["_links"] = map[string]interface{}{"pb:consumer-version": map[string]interface{}{"name": version}}
+	data, err := marshalPactDocument(doc)
+	require.NoError(s.t, err)
+	require.NoError(s.t, os.WriteFile(s.pactFile, data, 0o600))
+	return s
+}
+
+func (s *verificationReportStage) the_verification_is_written() *verificationReportStage {
+	require.Len(s.t, s.results, 1)
+	require.NoError(s.t, writeVerification(s.dir, "testservicea.json", providerVersionInfo{version: "1.2.3"},
+		s.results[0], s.responses))
+
+	dir := filepath.Join(s.dir, "build", "pact-verifications")
+	data, err := os.ReadFile(filepath.Join(dir, "testservicea.json"))
+	require.NoError(s.t, err)
+	require.NoError(s.t, json.Unmarshal(data, &s.written))
+	data, err = os.ReadFile(filepath.Join(dir, "output-testservicea.json"))
+	require.NoError(s.t, err)
+	require.NoError(s.t, json.Unmarshal(data, &s.output))
+	return s
+}
+
+func (s *verificationReportStage) the_result_is(success bool, consumer, consumerVersion string) *verificationReportStage {
+	require.Len(s.t, s.results, 1)
+	result := s.results[0]
+	assert.Equal(s.t, success, result.Success)
+	assert.Equal(s.t, consumer, result.Consumer)
+	assert.Equal(s.t, consumerVersion, result.ConsumerVersion)
+	assert.False(s.t, result.StartedAt.IsZero())
+	assert.False(s.t, result.FinishedAt.Before(result.StartedAt))
+	return s
+}
+
+func (s *verificationReportStage) the_result_has_interaction(description, status string, duration float64) *verificationReportStage {
+	for _, interaction := range s.results[0].Interactions {
+		if interaction.Description == description {
+			assert.Equal(s.t, status, interaction.Status)
+			assert.Equal(s.t, duration, interaction.Duration)
+			return s
+		}
+	}
+	s.t.Errorf("no interaction %q", description)
+	return s
+}
+
+func (s *verificationReportStage) the_written_verification_is_successful(success bool) *verificationReportStage {
+	assert.Equal(s.t, success, s.written["success"])
+	assert.Equal(s.t, "1.2.3", s.written["providerApplicationVersion"])
+	return s
+}
+
+func (s *verificationReportStage) the_written_verification_has_results(consumerVersion string, interactions ...string) *verificationReportStage {
+	results, _ := s.written["testResults"].(map[string]interface{})
+	require.NotNil(s.t, results)
+	assert.Equal(s.t, "consumera", results["consumer"])
+	if consumerVersion == "" {
+		assert.NotContains(s.t, results, "consumerVersion")
+	} else {
+		assert.Equal(s.t, consumerVersion, results["consumerVersion"])
+	}
+	assert.NotEmpty(s.t, results["startedAt"])
+	assert.NotEmpty(s.t, results["finishedAt"])
+
+	written, _ := results["interactions"].([]interface{})
+	require.Len(s.t, written, len(interactions))
+	for i, description := range interactions {
+		interaction, _ := written[i].(map[string]interface{})
+		assert.Equal(s.t, description, interaction["description"])
+		assert.Contains(s.t, interaction, "status")
+		assert.Contains(s.t, interaction, "duration")
+	}
+	return s
+}
+
+func (s *verificationReportStage) the_written_output_has_examples(count int) *verificationReportStage {
+	examples, _ := s.output["examples"].([]interface{})
+	assert.Len(s.t, examples, count)
+	summary, _ := s.output["summary"].(map[string]interface{})
+	assert.Equal(s.t, float64(count), summary["example_count"])
+	return s
+}
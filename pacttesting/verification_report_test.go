@@ -0,0 +1,91 @@
+package pacttesting
+
+import "testing"
+
+func TestVerificationReport_InteractionsFromAllResponsesAreReported(t *testing.T) {
+	given, when, then := VerificationReportTest(t)
+
+	given.
+		verifier_responses_with_a_failure()
+
+	when.
+		the_results_are_collected().and().
+		the_reports_are_written()
+
+	then.
+		the_junit_report_has_cases(2, 1, 0).and().
+		the_junit_case_failed_with("get an organisation as a reader", "Diff", `+  "status": 403`).and().
+		the_summary_has("success", false).and().
+		the_summary_has("providerVersion", "1.2.3").and().
+		the_summary_has("interactions", float64(2)).and().
+		the_summary_has("failures", float64(1)).and().
+		the_summarised_pact_has("consumer", "consumera").and().
+		the_summarised_pact_has("provider", "testservicea")
+}
+
+func TestVerificationReport_VerifierErrorIsReported(t *testing.T) {
+	given, when, then := VerificationReportTest(t)
+
+	given.
+		the_verifier_failed_to_run()
+
+	when.
+		the_results_are_collected().and().
+		the_reports_are_written()
+
+	then.
+		the_junit_report_has_cases(1, 0, 1).and().
+		the_junit_report_has_a_verification_error().and().
+		the_summary_has("success", false).and().
+		the_summarised_pact_has("error", "pact-provider-verifier: exit status 1")
+}
+
+func TestVerificationReport_NoPactsIsASuccessfulEmptySummary(t *testing.T) {
+	_, when, then := VerificationReportTest(t)
+
+	when.
+		the_reports_are_written()
+
+	then.
+		the_junit_report_has_cases(0, 0, 0).and().
+		the_summary_has("success", true).and().
+		the_summary_has("pacts", []interface{}{})
+}
+
+func TestVerificationReport_ResultAggregatesAllResponses(t *testing.T) {
+	given, when, then := VerificationReportTest(t)
+
+	given.
+		the_pact_was_fetched_from_a_broker_for_consumer_version("4.5.6").and().
+		verifier_responses_with_a_failure()
+
+	when.
+		the_results_are_collected().and().
+		the_verification_is_written()
+
+	then.
+		the_result_is(false, "consumera", "4.5.6").and().
+		the_result_has_interaction("get an organisation as an admin", InteractionPassed, 0.25).and().
+		the_result_has_interaction("get an organisation as a reader", InteractionFailed, 0).and().
+		the_written_verification_is_successful(false).and().
+		the_written_verification_has_results("4.5.6",
+			"get an organisation as an admin", "get an organisation as a reader").and().
+		the_written_output_has_examples(2)
+}
+
+func TestVerificationReport_VerifierErrorFailsTheVerification(t *testing.T) {
+	given, when, then := VerificationReportTest(t)
+
+	given.
+		the_verifier_failed_to_run()
+
+	when.
+		the_results_are_collected().and().
+		the_verification_is_written()
+
+	then.
+		the_result_is(false, "consumera", "").and().
+		the_written_verification_is_successful(false).and().
+		the_written_verification_has_results("").and().
+		the_written_output_has_examples(0)
+}